@@ -0,0 +1,173 @@
+package beelite
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethersphere/bee/v2/pkg/accounting"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// PeerSettlement holds the amounts settled with a single peer, both through
+// pseudosettle (time based refreshment) and SWAP (cheques).
+type PeerSettlement struct {
+	Peer                 swarm.Address
+	PseudosettleSent     *big.Int
+	PseudosettleReceived *big.Int
+	SwapSent             *big.Int
+	SwapReceived         *big.Int
+	LastSentCheque       *chequebook.SignedCheque
+	LastReceivedCheque   *chequebook.SignedCheque
+}
+
+// Settlements is a summary of all settlements of the node.
+type Settlements struct {
+	TotalSent     *big.Int
+	TotalReceived *big.Int
+	Peers         []PeerSettlement
+}
+
+// Balances returns the balance of every known peer keyed by overlay address,
+// decreased by the surplus balance. A positive value means the peer owes us,
+// a negative value means we are in debt to the peer.
+func (bl *Beelite) Balances() (map[string]*big.Int, error) {
+	balances, err := bl.accounting.CompensatedBalances()
+	if err != nil {
+		return nil, fmt.Errorf("get balances: %w", err)
+	}
+	return balances, nil
+}
+
+// PeerBalance returns the compensated balance of a single peer. It returns
+// accounting.ErrPeerNoBalance if no balance was recorded for the peer.
+func (bl *Beelite) PeerBalance(overlay swarm.Address) (*big.Int, error) {
+	balance, err := bl.accounting.CompensatedBalance(overlay)
+	if err != nil {
+		return nil, fmt.Errorf("get peer balance: %w", err)
+	}
+	return balance, nil
+}
+
+// ConsumedBalances returns the balance of every known peer keyed by overlay
+// address without the surplus balance applied.
+func (bl *Beelite) ConsumedBalances() (map[string]*big.Int, error) {
+	balances, err := bl.accounting.Balances()
+	if err != nil {
+		return nil, fmt.Errorf("get consumed balances: %w", err)
+	}
+	return balances, nil
+}
+
+// PeerAccounting returns the accounting state of the connected peers including
+// the payment thresholds, which is useful to understand why requests to a peer
+// are refused.
+func (bl *Beelite) PeerAccounting() (map[string]accounting.PeerInfo, error) {
+	info, err := bl.accounting.PeerAccounting()
+	if err != nil {
+		return nil, fmt.Errorf("get peer accounting: %w", err)
+	}
+	return info, nil
+}
+
+// Settlements returns the pseudosettle totals and, if SWAP is enabled, the
+// cheque totals together with the last cheques exchanged with every peer.
+func (bl *Beelite) Settlements() (*Settlements, error) {
+	peers := make(map[string]*PeerSettlement)
+	peer := func(addr string) (*PeerSettlement, error) {
+		if p, ok := peers[addr]; ok {
+			return p, nil
+		}
+		overlay, err := swarm.ParseHexAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("parse peer address %q: %w", addr, err)
+		}
+		p := &PeerSettlement{
+			Peer:                 overlay,
+			PseudosettleSent:     big.NewInt(0),
+			PseudosettleReceived: big.NewInt(0),
+			SwapSent:             big.NewInt(0),
+			SwapReceived:         big.NewInt(0),
+		}
+		peers[addr] = p
+		return p, nil
+	}
+
+	res := &Settlements{
+		TotalSent:     big.NewInt(0),
+		TotalReceived: big.NewInt(0),
+	}
+
+	sent, err := bl.pseudosettle.SettlementsSent()
+	if err != nil {
+		return nil, fmt.Errorf("pseudosettle sent settlements: %w", err)
+	}
+	for addr, amount := range sent {
+		p, err := peer(addr)
+		if err != nil {
+			return nil, err
+		}
+		p.PseudosettleSent.Set(amount)
+		res.TotalSent.Add(res.TotalSent, amount)
+	}
+
+	received, err := bl.pseudosettle.SettlementsReceived()
+	if err != nil {
+		return nil, fmt.Errorf("pseudosettle received settlements: %w", err)
+	}
+	for addr, amount := range received {
+		p, err := peer(addr)
+		if err != nil {
+			return nil, err
+		}
+		p.PseudosettleReceived.Set(amount)
+		res.TotalReceived.Add(res.TotalReceived, amount)
+	}
+
+	if bl.swap != nil {
+		sent, err := bl.swap.SettlementsSent()
+		if err != nil {
+			return nil, fmt.Errorf("swap sent settlements: %w", err)
+		}
+		for addr, amount := range sent {
+			p, err := peer(addr)
+			if err != nil {
+				return nil, err
+			}
+			p.SwapSent.Set(amount)
+			res.TotalSent.Add(res.TotalSent, amount)
+		}
+
+		received, err := bl.swap.SettlementsReceived()
+		if err != nil {
+			return nil, fmt.Errorf("swap received settlements: %w", err)
+		}
+		for addr, amount := range received {
+			p, err := peer(addr)
+			if err != nil {
+				return nil, err
+			}
+			p.SwapReceived.Set(amount)
+			res.TotalReceived.Add(res.TotalReceived, amount)
+		}
+
+		for _, p := range peers {
+			p.LastSentCheque, err = bl.swap.LastSentCheque(p.Peer)
+			if err != nil && !errors.Is(err, chequebook.ErrNoCheque) {
+				bl.logger.Debug("settlements: last sent cheque failed", "peer_address", p.Peer, "error", err)
+			}
+			p.LastReceivedCheque, err = bl.swap.LastReceivedCheque(p.Peer)
+			if err != nil && !errors.Is(err, chequebook.ErrNoCheque) {
+				bl.logger.Debug("settlements: last received cheque failed", "peer_address", p.Peer, "error", err)
+			}
+		}
+	}
+
+	res.Peers = make([]PeerSettlement, 0, len(peers))
+	for _, p := range peers {
+		res.Peers = append(res.Peers, *p)
+	}
+
+	return res, nil
+}
@@ -1,10 +1,15 @@
 package beelite
 
 import (
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/postage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
 
 func (bl *Beelite) GetAllBatches() []*postage.StampIssuer {
@@ -34,6 +39,91 @@ func (bl *Beelite) GetUsableBatches() []*postage.StampIssuer {
 	return usableIssuers
 }
 
+// PostageSyncSource returns the source the postage batches were synced from,
+// one of the PostageSource constants, or empty while the node is still
+// retrieving the snapshot feed.
+func (bl *Beelite) PostageSyncSource() string {
+	return bl.bee.postageSyncSource()
+}
+
 func (bl *Beelite) BuyStamp(amount *big.Int, depth uint64, label string, immutable bool) (common.Hash, []byte, error) {
+	if !bl.chainEnabled() {
+		return common.Hash{}, nil, ErrChainDisabled
+	}
 	return bl.postageContract.CreateBatch(bl.ctx, amount, uint8(depth), immutable, label)
 }
+
+// IssueStamps signs a stamp of the batch for every chunk address and returns
+// them marshalled, in the format accepted by AddChunk and AddSOC. The stamps
+// take up the slots of the batch buckets as if the chunks were uploaded, so
+// the batch owner can hand them to uploaders which do not own the batch.
+func (bl *Beelite) IssueStamps(batchHex string, chunkAddresses []swarm.Address) ([][]byte, error) {
+	if !bl.chainEnabled() {
+		return nil, ErrChainDisabled
+	}
+	batchID, err := hex.DecodeString(batchHex)
+	if err != nil {
+		return nil, errInvalidPostageBatch
+	}
+
+	var signer crypto.Signer = bl.signer
+	stampSigner := bl.stampSigners.get(batchID)
+	if stampSigner != nil {
+		signer = deferredSigner{}
+	}
+
+	stamper, save, err := bl.getStamper(batchID, signer)
+	if err != nil {
+		return nil, fmt.Errorf("get stamper: %w", err)
+	}
+
+	stamps := make([]swarm.Stamp, 0, len(chunkAddresses))
+	for _, addr := range chunkAddresses {
+		stamp, err := stamper.Stamp(addr, addr)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("stamp chunk %s: %w", addr, err), save())
+		}
+		stamps = append(stamps, stamp)
+	}
+	if err := save(); err != nil {
+		return nil, fmt.Errorf("save stamp issuer: %w", err)
+	}
+
+	if stampSigner != nil {
+		for i := 0; i < len(stamps); i += stampSignBatchSize {
+			end := min(i+stampSignBatchSize, len(stamps))
+			signed, err := stampSigner.sign(bl.ctx, chunkAddresses[i:end], stamps[i:end])
+			if err != nil {
+				return nil, err
+			}
+			for j, st := range signed {
+				stamps[i+j] = st
+			}
+		}
+	}
+
+	res := make([][]byte, len(stamps))
+	for i, st := range stamps {
+		if res[i], err = st.MarshalBinary(); err != nil {
+			return nil, fmt.Errorf("marshal stamp: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// ValidateStamp checks that the marshalled stamp is a valid stamp of a known
+// batch for the chunk address and is signed by the owner of the batch.
+func (bl *Beelite) ValidateStamp(chunkAddress swarm.Address, stampBytes []byte) error {
+	if !bl.chainEnabled() {
+		return ErrChainDisabled
+	}
+	stamp := postage.Stamp{}
+	if err := stamp.UnmarshalBinary(stampBytes); err != nil {
+		return fmt.Errorf("unmarshal stamp: %w", err)
+	}
+	batch, err := bl.batchStore.Get(stamp.BatchID())
+	if err != nil {
+		return fmt.Errorf("get batch: %w", err)
+	}
+	return stamp.Valid(chunkAddress, batch.Owner, batch.Depth, batch.BucketDepth, batch.Immutable)
+}
@@ -45,7 +45,6 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/pullsync"
 	"github.com/ethersphere/bee/v2/pkg/pusher"
 	"github.com/ethersphere/bee/v2/pkg/pushsync"
-	"github.com/ethersphere/bee/v2/pkg/resolver/multiresolver"
 	"github.com/ethersphere/bee/v2/pkg/retrieval"
 	"github.com/ethersphere/bee/v2/pkg/salud"
 	"github.com/ethersphere/bee/v2/pkg/settlement/pseudosettle"
@@ -69,7 +68,6 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/transaction"
 	"github.com/ethersphere/bee/v2/pkg/util/abiutil"
 	"github.com/ethersphere/bee/v2/pkg/util/ioutil"
-	"github.com/ethersphere/bee/v2/pkg/util/nbhdutil"
 	"github.com/ethersphere/bee/v2/pkg/util/syncutil"
 	"github.com/hashicorp/go-multierror"
 	ma "github.com/multiformats/go-multiaddr"
@@ -113,6 +111,8 @@ type Bee struct {
 	shutdownMutex            sync.Mutex
 	syncingStopped           *syncutil.Signaler
 	accesscontrolCloser      io.Closer
+	events                   *eventBus
+	postageSource            atomic.Value
 }
 
 const (
@@ -147,6 +147,7 @@ func NewBee(
 	libp2pPrivateKey,
 	pssPrivateKey *ecdsa.PrivateKey,
 	session accesscontrol.Session,
+	lno *liteNodeOptions,
 	o *node.Options,
 ) (bl *Beelite, err error) {
 	tracer, tracerCloser, err := tracing.NewTracer(&tracing.Options{
@@ -184,6 +185,7 @@ func NewBee(
 		errorLogWriter: sink,
 		tracerCloser:   tracerCloser,
 		syncingStopped: syncutil.NewSignaler(),
+		events:         newEventBus(),
 	}
 
 	defer func(b *Bee) {
@@ -227,41 +229,48 @@ func NewBee(
 	}
 
 	targetNeighborhood := o.TargetNeighborhood
-	if targetNeighborhood == "" && !nonceExists && o.NeighborhoodSuggester != "" {
-		logger.Info("fetching target neighborhood from suggester", "url", o.NeighborhoodSuggester)
-		targetNeighborhood, err = nbhdutil.FetchNeighborhood(&http.Client{}, o.NeighborhoodSuggester)
+	if targetNeighborhood == "" && !nonceExists && lno.neighborhoodSuggester != nil {
+		logger.Info("fetching target neighborhood from suggester")
+		targetNeighborhood, err = lno.neighborhoodSuggester(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("neighborhood suggestion: %w", err)
 		}
 	}
 
-	var changedOverlay, resetReserve bool
+	// an overlay mined by MineOverlay before the start
+	changedOverlay, err := overlayChanged(stateStore)
+	if err != nil {
+		return nil, fmt.Errorf("statestore: overlay change: %w", err)
+	}
+	resetReserve := changedOverlay
+
 	if targetNeighborhood != "" {
-		neighborhood, err := swarm.ParseBitStrAddress(targetNeighborhood)
+		inTarget, err := inNeighborhood(swarmAddress, targetNeighborhood)
 		if err != nil {
-			return nil, fmt.Errorf("invalid neighborhood. %s", targetNeighborhood)
+			return nil, err
 		}
 
-		if swarm.Proximity(swarmAddress.Bytes(), neighborhood.Bytes()) < uint8(len(targetNeighborhood)) {
+		if !inTarget {
 			// mine the overlay
 			logger.Info("mining a new overlay address to target the selected neighborhood", "target", targetNeighborhood)
-			newSwarmAddress, newNonce, err := nbhdutil.MineOverlay(ctx, *pubKey, networkID, targetNeighborhood)
+			newSwarmAddress, newNonce, err := mineOverlay(ctx, *pubKey, networkID, targetNeighborhood, func(attempts uint64) {
+				logger.Debug("mining overlay address", "attempts", attempts)
+			})
 			if err != nil {
 				return nil, fmt.Errorf("mine overlay address: %w", err)
 			}
 
 			if nonceExists {
 				logger.Info("Override nonce and clean state for neighborhood", "old_none", hex.EncodeToString(nonce), "new_nonce", hex.EncodeToString(newNonce))
-				logger.Warning("you have another 10 seconds to change your mind and kill this process with CTRL-C...")
-				time.Sleep(10 * time.Second)
-
-				err := ioutil.RemoveContent(filepath.Join(o.DataDir, ioutil.DataPathKademlia))
-				if err != nil {
-					return nil, fmt.Errorf("delete %s: %w", ioutil.DataPathKademlia, err)
+				if lno.confirmOverlayChange != nil && !lno.confirmOverlayChange(swarmAddress, newSwarmAddress) {
+					return nil, ErrOverlayChangeRejected
 				}
 
-				if err := stateStore.ClearForHopping(); err != nil {
-					return nil, fmt.Errorf("clearing stateStore %w", err)
+				if err := clearNeighborhood(o.DataDir, stateStore); err != nil {
+					return nil, err
+				}
+				if err := stateStore.Put(overlayChangedKey, true); err != nil {
+					return nil, fmt.Errorf("statestore: mark overlay change: %w", err)
 				}
 				resetReserve = true
 			}
@@ -325,7 +334,7 @@ func NewBee(
 		}
 	}
 
-	chainBackend, overlayEthAddress, chainID, transactionMonitor, transactionService, err = node.InitChain(
+	chainBackend, overlayEthAddress, chainID, transactionMonitor, transactionService, err = initChain(
 		ctx,
 		logger,
 		stateStore,
@@ -333,7 +342,8 @@ func NewBee(
 		o.ChainID,
 		signer,
 		o.BlockTime,
-		chainEnabled)
+		chainEnabled,
+		lno.httpClient)
 	if err != nil {
 		return nil, fmt.Errorf("init chain: %w", err)
 	}
@@ -345,6 +355,12 @@ func NewBee(
 		return nil, fmt.Errorf("connected to wrong blockchain network; network chainID %d; configured chainID %d", chainID, o.ChainID)
 	}
 
+	if chainEnabled {
+		if err := verifyContracts(ctx, chainBackend, o); err != nil {
+			return nil, fmt.Errorf("verify contracts: %w", err)
+		}
+	}
+
 	b.transactionCloser = tracerCloser
 	b.transactionMonitorCloser = transactionMonitor
 
@@ -428,17 +444,19 @@ func NewBee(
 		b.apiCloser = apiServer
 	}
 
-	// Sync the with the given Ethereum backend:
-	isSynced, _, err := transaction.IsSynced(ctx, chainBackend, maxDelay)
-	if err != nil {
-		return nil, fmt.Errorf("is synced: %w", err)
-	}
-	if !isSynced {
-		logger.Info("waiting to sync with the blockchain backend")
-
-		err := transaction.WaitSynced(ctx, logger, chainBackend, maxDelay)
+	// Sync the with the given Ethereum backend, there is nothing to wait for in ultra-light mode:
+	if chainEnabled {
+		isSynced, _, err := transaction.IsSynced(ctx, chainBackend, maxDelay)
 		if err != nil {
-			return nil, fmt.Errorf("waiting backend sync: %w", err)
+			return nil, fmt.Errorf("is synced: %w", err)
+		}
+		if !isSynced {
+			logger.Info("waiting to sync with the blockchain backend")
+
+			err := transaction.WaitSynced(ctx, logger, chainBackend, maxDelay)
+			if err != nil {
+				return nil, fmt.Errorf("waiting backend sync: %w", err)
+			}
 		}
 	}
 
@@ -546,33 +564,31 @@ func NewBee(
 		logger.Debug("node warmup check: period complete.", "periodEndTime", t, "eventsInPeriod", periodCount, "rateStdDev", stDev)
 	}
 
-	var initBatchState *postage.ChainSnapshot
-	// Bootstrap node with postage snapshot only if it is running on mainnet, is a fresh
-	// install or explicitly asked by user to resync
-	if networkID == mainnetNetworkID && o.UsePostageSnapshot && (!batchStoreExists || o.Resync) {
-		start := time.Now()
-		logger.Info("cold postage start detected. fetching postage stamp snapshot from swarm")
-		initBatchState, err = bootstrapNode(
-			ctx,
-			addr,
-			swarmAddress,
-			nonce,
-			addressbook,
-			bootnodes,
-			lightNodes,
-			stateStore,
-			signer,
-			networkID,
-			log.Noop,
-			libp2pPrivateKey,
-			detector,
-			o,
-		)
-		logger.Info("bootstrapper created", "elapsed", time.Since(start))
+	// The postage snapshots in the order they are tried, the batches are
+	// synced from the blockchain if none of them can be used.
+	var snapshots []postageSnapshot
+	if lno.snapshotGetter != nil {
+		snapshots = append(snapshots, postageSnapshot{source: lno.snapshotSource, getter: lno.snapshotGetter})
+	}
+	if networkID == mainnetNetworkID {
+		snapshots = append(snapshots, postageSnapshot{source: PostageSourceArchive, getter: archiveSnapshotGetter{}})
+	}
+	// Sync a new node from the postage snapshot on mainnet, or on other
+	// networks if a snapshot was configured.
+	useSnapshot := !o.SkipPostageSnapshot && !batchStoreExists
+	// A node with synced batches catches up from the snapshot deltas, unless
+	// its batch store is reset.
+	useDeltas := !o.SkipPostageSnapshot && batchStoreExists && !o.Resync && len(lno.snapshotDeltas) > 0
+	if useDeltas {
+		dirty, err := batchStoreDirty(stateStore)
 		if err != nil {
-			logger.Error(err, "bootstrapper failed to fetch batch state")
+			return nil, fmt.Errorf("batchstore: %w", err)
 		}
+		useDeltas = !dirty
 	}
+	// The feed is retrieved over the network of the node once it started,
+	// only light nodes can use it.
+	useFeed := useSnapshot && !lno.snapshotFeed.IsZero()
 
 	var registry *prometheus.Registry
 
@@ -604,7 +620,8 @@ func NewBee(
 		return nil, fmt.Errorf("postage service: %w", err)
 	}
 	b.postageServiceCloser = post
-	batchStore.SetBatchExpiryHandler(post)
+	batchStore.SetBatchExpiryHandler(&batchExpiryNotifier{BatchExpiryHandler: post, bus: b.events})
+	batchListener := &batchEventNotifier{BatchEventListener: post, bus: b.events}
 
 	var (
 		postageStampContractService postagecontract.Interface
@@ -613,6 +630,9 @@ func NewBee(
 	)
 
 	chainCfg, found := config.GetByChainID(chainID)
+	if !found {
+		chainCfg = customChainConfig(chainID, networkID)
+	}
 	postageStampContractAddress, postageSyncStart := chainCfg.PostageStampAddress, chainCfg.PostageStampStartBlock
 	if o.PostageContractAddress != "" {
 		if !common.IsHexAddress(o.PostageContractAddress) {
@@ -649,7 +669,7 @@ func NewBee(
 	eventListener = listener.New(b.syncingStopped, logger, chainBackend, postageStampContractAddress, postageStampContractABI, o.BlockTime, postageSyncingStallingTimeout, postageSyncingBackoffTimeout)
 	b.listenerCloser = eventListener
 
-	batchSvc, err = batchservice.New(stateStore, batchStore, logger, eventListener, overlayEthAddress.Bytes(), post, sha3.New256, o.Resync)
+	batchSvc, err = batchservice.New(stateStore, batchStore, logger, eventListener, overlayEthAddress.Bytes(), batchListener, sha3.New256, o.Resync)
 	if err != nil {
 		return nil, fmt.Errorf("init batch service: %w", err)
 	}
@@ -746,34 +766,85 @@ func NewBee(
 		}
 	)
 
-	if !o.SkipPostageSnapshot && !batchStoreExists && (networkID == mainnetNetworkID) {
-		chainBackend := node.NewSnapshotLogFilterer(logger, archiveSnapshotGetter{})
+	snapshotMetrics := newSnapshotMetrics()
+	if (useSnapshot || useDeltas) && apiService != nil {
+		apiService.MustRegisterMetrics(metrics.PrometheusCollectorsFromFields(snapshotMetrics)...)
+	}
+
+	// syncFromSnapshot syncs the batches from the snapshot merged with the
+	// deltas and moves the start of the sync from the blockchain after it. It
+	// reports whether the snapshot could be used.
+	syncFromSnapshot := func(snapshotGetter SnapshotGetter) bool {
+		chainBackend := NewSnapshotLogFilterer(logger, snapshotGetter)
+		if lno.streamSnapshot {
+			chainBackend = NewStreamingSnapshotLogFilterer(logger, snapshotGetter, o.DataDir)
+		}
+		chainBackend.SetTrustedSigners(lno.snapshotSigners)
+		chainBackend.AddSnapshotDeltas(lno.snapshotDeltas...)
+		chainBackend.metrics = snapshotMetrics
+		defer func() {
+			if err := chainBackend.Close(); err != nil {
+				logger.Error(err, "failed to free postage snapshot")
+			}
+		}()
+
+		if err := chainBackend.checkResume(ctx, max(postageSyncStart, batchStore.GetChainState().Block)); err != nil {
+			logger.Error(err, "postage snapshot cannot be used, continuing outside snapshot block...")
+			return false
+		}
 
 		snapshotEventListener := listener.New(b.syncingStopped, logger, chainBackend, postageStampContractAddress, postageStampContractABI, o.BlockTime, postageSyncingStallingTimeout, postageSyncingBackoffTimeout)
 
-		snapshotBatchSvc, err := batchservice.New(stateStore, batchStore, logger, snapshotEventListener, overlayEthAddress.Bytes(), post, sha3.New256, o.Resync)
+		// the batches of a failed snapshot are only dropped if the store
+		// had none before, a synced store resumes from its chain state
+		storeEmpty := batchStore.GetChainState().Block == 0 || o.Resync
+
+		used := false
+		snapshotBatchSvc, err := batchservice.New(stateStore, batchStore, logger, snapshotEventListener, overlayEthAddress.Bytes(), batchListener, sha3.New256, o.Resync)
 		if err != nil {
 			logger.Error(err, "failed to initialize batch service from snapshot, continuing outside snapshot block...")
 		} else {
-			err = snapshotBatchSvc.Start(ctx, postageSyncStart, initBatchState)
+			err = snapshotBatchSvc.Start(ctx, postageSyncStart, nil)
 			syncStatus.Store(true)
 			if err != nil {
-				syncErr.Store(err)
+				// only the error of the final source is reported, the
+				// batches of the snapshot are dropped for the next one
 				logger.Error(err, "failed to start batch service from snapshot, continuing outside snapshot block...")
+				if storeEmpty {
+					if err := batchStore.Reset(); err != nil {
+						logger.Error(err, "failed to reset batch store after the snapshot")
+					}
+				}
 			} else {
 				postageSyncStart, err = chainBackend.BlockNumber(ctx)
 				if err != nil {
 					logger.Error(err, "failed to initialize batch service from snapshot: failed to get block number...")
 				}
+				used = err == nil
 			}
 		}
 		if errClose := snapshotEventListener.Close(); errClose != nil {
 			logger.Error(errClose, "failed to close event listener (snapshot) failure")
 		}
+		return used
+	}
 
+	// syncFromSnapshots tries the snapshots in order and returns the source
+	// of the batches.
+	syncFromSnapshots := func(snapshots []postageSnapshot) string {
+		for _, s := range snapshots {
+			logger.Info("syncing postage batches from snapshot", "source", s.source)
+			if syncFromSnapshot(s.getter) {
+				return s.source
+			}
+		}
+		return PostageSourceChain
 	}
 
-	if batchSvc != nil && chainEnabled {
+	syncFromChain := func() error {
+		if batchSvc == nil || !chainEnabled {
+			return nil
+		}
 		logger.Info("waiting to sync postage contract data, this may take a while... more info available in Debug loglevel")
 
 		paused, err := postageStampContractService.Paused(ctx)
@@ -782,20 +853,20 @@ func NewBee(
 		}
 
 		if paused {
-			return nil, errors.New("postage contract is paused")
+			return errors.New("postage contract is paused")
 		}
 
 		if o.FullNodeMode {
-			err = batchSvc.Start(ctx, postageSyncStart, initBatchState)
+			err = batchSvc.Start(ctx, postageSyncStart, nil)
 			syncStatus.Store(true)
 			if err != nil {
 				syncErr.Store(err)
-				return nil, fmt.Errorf("unable to start batch service: %w", err)
+				return fmt.Errorf("unable to start batch service: %w", err)
 			}
 		} else {
 			go func() {
 				logger.Info("started postage contract data sync in the background...")
-				err := batchSvc.Start(ctx, postageSyncStart, initBatchState)
+				err := batchSvc.Start(ctx, postageSyncStart, nil)
 				syncStatus.Store(true)
 				if err != nil {
 					syncErr.Store(err)
@@ -804,7 +875,24 @@ func NewBee(
 				}
 			}()
 		}
-
+		return nil
+	}
+
+	if !useFeed {
+		source := PostageSourceChain
+		switch {
+		case useSnapshot:
+			source = syncFromSnapshots(snapshots)
+		case useDeltas:
+			logger.Info("syncing postage batches from snapshot deltas", "count", len(lno.snapshotDeltas))
+			if syncFromSnapshot(nil) {
+				source = PostageSourceDeltas
+			}
+		}
+		b.setPostageSource(source, logger)
+		if err := syncFromChain(); err != nil {
+			return nil, err
+		}
 	}
 
 	minThreshold := big.NewInt(2 * refreshRate)
@@ -938,6 +1026,9 @@ func NewBee(
 				if prev == uint32(swarm.MaxBins) {
 					close(initialRadiusC)
 				}
+				if prev != uint32(r) {
+					b.events.publish(Event{Kind: EventStorageRadiusChanged, Radius: r})
+				}
 				if !o.FullNodeMode { // light and ultra-light nodes do not have a reserve worker to set the radius.
 					kad.SetStorageRadius(r)
 				}
@@ -1017,6 +1108,7 @@ func NewBee(
 		<-sub
 		logger.Info("node warmup stabilization complete, updating API status")
 		apiService.SetIsWarmingUp(false)
+		b.events.publish(Event{Kind: EventWarmupFinished})
 	}()
 
 	stakingContractAddress := chainCfg.StakingAddress
@@ -1064,9 +1156,17 @@ func NewBee(
 		}
 	}
 
+	// the reserve is reset and the staking contract has the new overlay
+	if resetReserve {
+		if err := clearOverlayChanged(stateStore); err != nil {
+			return nil, fmt.Errorf("statestore: clear overlay change: %w", err)
+		}
+	}
+
 	var (
-		pullerService *puller.Puller
-		agent         *storageincentives.Agent
+		pullerService     *puller.Puller
+		agent             *storageincentives.Agent
+		redistributionCtl *redistributionControl
 	)
 
 	if o.FullNodeMode && !o.BootnodeMode {
@@ -1093,6 +1193,7 @@ func NewBee(
 				logger.Debug("Sync status check evaluated", "stabilized", detector.IsStabilized())
 				return localStore.ReserveSize() >= reserveTreshold && pullerService.SyncRate() == 0 && detector.IsStabilized()
 			}
+			redistributionCtl = &redistributionControl{isFullySynced: isFullySynced}
 
 			agent, err = storageincentives.New(
 				swarmAddress,
@@ -1102,7 +1203,7 @@ func NewBee(
 				postageStampContractService,
 				stakingContract,
 				localStore,
-				isFullySynced,
+				redistributionCtl.participate,
 				o.BlockTime,
 				storageincentives.DefaultBlocksPerRound,
 				storageincentives.DefaultBlocksPerPhase,
@@ -1120,11 +1221,7 @@ func NewBee(
 		}
 
 	}
-	multiResolver := multiresolver.NewMultiResolver(
-		multiresolver.WithConnectionConfigs(o.ResolverConnectionCfgs),
-		multiresolver.WithLogger(o.Logger),
-		multiresolver.WithDefaultCIDResolver(),
-	)
+	multiResolver, addressResolver := newMultiResolver(ctx, o.ResolverConnectionCfgs, o.Logger, lno.httpClient)
 	b.resolverCloser = multiResolver
 
 	feedFactory := factory.New(localStore.Download(true))
@@ -1221,25 +1318,62 @@ func NewBee(
 		return nil, fmt.Errorf("p2ps ready: %w", err)
 	}
 
-		bl = &Beelite{
-			bee:                b,
-			overlayEthAddress:  overlayEthAddress,
-			publicKey:          publicKey,
-			feedFactory:        feedFactory,
-			logger:             logger,
-			storer:             localStore,
-			topologyDriver:     kad,
-			ctx:                ctx,
-			accesscontrol:      accesscontrol,
-			chequebookSvc:      chequebookService,
-			post:               post,
-			signer:             signer,
-			stamperStore:       stamperStore,
-			batchStore:         batchStore,
-			postageContract:    postageStampContractService,
-			beeNodeMode:        beeNodeMode,
-			transactionService: transactionService,
-		}
+	if useFeed {
+		go func() {
+			candidates := snapshots
+			feedSnapshot, err := fetchSnapshotFeed(ctx, kad, localStore.Download(true), localStore.Cache(), lno.snapshotFeed, lno.snapshotSigners, logger)
+			if err != nil {
+				logger.Error(err, "failed to fetch postage snapshot from feed, falling back to the other sources...")
+			} else {
+				candidates = append([]postageSnapshot{{source: PostageSourceFeed, getter: feedSnapshot}}, snapshots...)
+			}
+
+			b.setPostageSource(syncFromSnapshots(candidates), logger)
+			if err := syncFromChain(); err != nil {
+				logger.Error(err, "unable to sync batches")
+				b.syncingStopped.Signal()
+			}
+		}()
+	}
+
+	go b.events.watchPeers(ctx, kad, p2ps)
+	go b.events.watchTags(ctx, localStore)
+	if chainEnabled {
+		go b.events.watchChainSync(ctx, chainBackend, batchStore, syncStatusFn)
+	}
+
+	bl = &Beelite{
+		bee:                 b,
+		overlayEthAddress:   overlayEthAddress,
+		publicKey:           publicKey,
+		feedFactory:         feedFactory,
+		logger:              logger,
+		storer:              localStore,
+		topologyDriver:      kad,
+		ctx:                 ctx,
+		accesscontrol:       accesscontrol,
+		chequebookSvc:       chequebookService,
+		post:                post,
+		signer:              signer,
+		stamperStore:        stamperStore,
+		batchStore:          batchStore,
+		postageContract:     postageStampContractService,
+		beeNodeMode:         beeNodeMode,
+		transactionService:  transactionService,
+		overlay:             swarmAddress,
+		p2pService:          p2ps,
+		pingpong:            pingPong,
+		addressBook:         addressbook,
+		stateStore:          stateStore,
+		networkID:           networkID,
+		addressResolver:     addressResolver,
+		accounting:          acc,
+		pseudosettle:        pseudosettleService,
+		swap:                swapService,
+		stakingContract:     stakingContract,
+		redistributionAgent: agent,
+		redistribution:      redistributionCtl,
+	}
 
 	return bl, nil
 }
@@ -1266,6 +1400,11 @@ func (b *Bee) Shutdown() error {
 	b.shutdownInProgress = true
 	b.shutdownMutex.Unlock()
 
+	// notify subscribers before the components go away
+	if b.events != nil {
+		b.events.close()
+	}
+
 	// halt kademlia while shutting down other
 	// components.
 	if b.topologyHalter != nil {
@@ -5,204 +5,164 @@
 package beelite
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
-	"crypto/ecdsa"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethersphere/bee/v2/pkg/accounting"
-	"github.com/ethersphere/bee/v2/pkg/addressbook"
-	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/feeds"
 	"github.com/ethersphere/bee/v2/pkg/feeds/factory"
 	"github.com/ethersphere/bee/v2/pkg/file"
 	"github.com/ethersphere/bee/v2/pkg/file/joiner"
 	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
 	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
-	"github.com/ethersphere/bee/v2/pkg/hive"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/manifest"
-	"github.com/ethersphere/bee/v2/pkg/node"
-	"github.com/ethersphere/bee/v2/pkg/p2p/libp2p"
 	"github.com/ethersphere/bee/v2/pkg/postage"
-	"github.com/ethersphere/bee/v2/pkg/pricer"
-	"github.com/ethersphere/bee/v2/pkg/pricing"
-	"github.com/ethersphere/bee/v2/pkg/retrieval"
-	"github.com/ethersphere/bee/v2/pkg/settlement/pseudosettle"
 	"github.com/ethersphere/bee/v2/pkg/spinlock"
-	"github.com/ethersphere/bee/v2/pkg/stabilization"
 	"github.com/ethersphere/bee/v2/pkg/storage"
-	"github.com/ethersphere/bee/v2/pkg/storer"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 	"github.com/ethersphere/bee/v2/pkg/topology"
 	"github.com/ethersphere/bee/v2/pkg/topology/kademlia"
-	"github.com/ethersphere/bee/v2/pkg/topology/lightnode"
-	"github.com/ethersphere/bee/v2/pkg/tracing"
-	"github.com/hashicorp/go-multierror"
-	ma "github.com/multiformats/go-multiaddr"
 )
 
-var (
-	// zeroed out while waiting to be  replacement for the new snapshot feed address
-	// must be different to avoid stale reads on the old contract
-	snapshotFeed    = swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
-	errDataMismatch = errors.New("data length mismatch")
+// The sources of the postage batches of the node, reported by
+// PostageSyncSource.
+const (
+	PostageSourceFeed    = "feed"
+	PostageSourceArchive = "archive"
+	PostageSourceFile    = "file"
+	PostageSourceURL     = "url"
+	PostageSourceCustom  = "custom"
+	PostageSourceDeltas  = "deltas"
+	PostageSourceChain   = "rpc"
 )
 
+var errDataMismatch = errors.New("data length mismatch")
+
+// batchServiceDirtyKey is the state store key the batch service marks an
+// unclean shutdown with, its batch store is reset on the next start.
+const batchServiceDirtyKey = "batchservice_dirty_db"
+
 const (
 	getSnapshotRetries = 3
 	retryWait          = time.Second * 5
 	timeout            = time.Minute * 2
+	minFeedPeers       = 3
+	feedPeersTimeout   = time.Minute
 )
 
-func bootstrapNode(
-	ctx context.Context,
-	addr string,
-	swarmAddress swarm.Address,
-	nonce []byte,
-	addressbook addressbook.Interface,
-	bootnodes []ma.Multiaddr,
-	lightNodes *lightnode.Container,
-	stateStore storage.StateStorer,
-	signer crypto.Signer,
-	networkID uint64,
-	logger log.Logger,
-	libp2pPrivateKey *ecdsa.PrivateKey,
-	detector *stabilization.Detector,
-	o *node.Options,
-) (snapshot *postage.ChainSnapshot, retErr error) {
-	tracer, tracerCloser, err := tracing.NewTracer(&tracing.Options{
-		Enabled:     o.TracingEnabled,
-		Endpoint:    o.TracingEndpoint,
-		ServiceName: o.TracingServiceName,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("tracer: %w", err)
-	}
+// postageSnapshot is a postage snapshot the batches can be synced from.
+type postageSnapshot struct {
+	source string
+	getter SnapshotGetter
+}
 
-	p2pCtx, p2pCancel := context.WithCancel(ctx)
+// FeedSnapshot is the content of the postage snapshot feed, either a snapshot
+// in the format of SnapshotLogFilterer or the JSON encoded postage chain
+// snapshot of bee, together with the manifest signed over it. Only this
+// wrapper is supported, a feed update holding a bare bee chain snapshot has
+// no signature and is rejected.
+type FeedSnapshot struct {
+	Manifest      *SnapshotManifest `json:"manifest"`
+	Snapshot      []byte            `json:"snapshot,omitempty"`
+	ChainSnapshot json.RawMessage   `json:"chainSnapshot,omitempty"`
+}
 
-	b := &Bee{
-		ctxCancel:    p2pCancel,
-		tracerCloser: tracerCloser,
+// parseFeedSnapshot returns the snapshot of the feed content. A snapshot is
+// verified by the SnapshotLogFilterer while it is read, a chain snapshot is
+// verified against the signers before it is converted to one.
+func parseFeedSnapshot(data []byte, signers []common.Address) (SnapshotGetter, error) {
+	content := &FeedSnapshot{}
+	if err := json.Unmarshal(data, content); err != nil {
+		return nil, fmt.Errorf("unmarshal feed snapshot: %w", err)
 	}
-
-	defer func() {
-		retErr = multierror.Append(new(multierror.Error), retErr, b.Shutdown()).ErrorOrNil()
-	}()
-
-	p2ps, err := libp2p.New(p2pCtx, signer, networkID, swarmAddress, addr, addressbook, stateStore, lightNodes, logger, tracer, libp2p.Options{
-		PrivateKey:     libp2pPrivateKey,
-		NATAddr:        o.NATAddr,
-		EnableWS:       o.EnableWS,
-		WelcomeMessage: o.WelcomeMessage,
-		FullNode:       false,
-		Nonce:          nonce,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("p2p service: %w", err)
+	if content.Manifest == nil {
+		return nil, fmt.Errorf("%w: no snapshot manifest", ErrSnapshotUntrusted)
 	}
-	b.p2pService = p2ps
-	b.p2pHalter = p2ps
-
-	hive := hive.New(p2ps, addressbook, networkID, o.BootnodeMode, o.AllowPrivateCIDRs, logger)
 
-	if err = p2ps.AddProtocol(hive.Protocol()); err != nil {
-		return nil, fmt.Errorf("hive service: %w", err)
+	switch {
+	case len(content.Snapshot) > 0:
+		return &feedSnapshotGetter{data: content.Snapshot, manifest: content.Manifest}, nil
+	case len(content.ChainSnapshot) > 0:
+		return newChainSnapshotGetter(content.ChainSnapshot, content.Manifest, signers)
+	default:
+		return nil, errors.New("feed snapshot has no content")
 	}
-	b.hiveCloser = hive
-
-	kad, err := kademlia.New(swarmAddress, addressbook, hive, p2ps, detector, logger,
-		kademlia.Options{Bootnodes: bootnodes, BootnodeMode: o.BootnodeMode, StaticNodes: o.StaticNodes, DataDir: o.DataDir})
-	if err != nil {
-		return nil, fmt.Errorf("unable to create kademlia: %w", err)
-	}
-	b.topologyCloser = kad
-	b.topologyHalter = kad
-	hive.SetAddPeersHandler(kad.AddPeers)
-	p2ps.SetPickyNotifier(kad)
-
-	paymentThreshold, _ := new(big.Int).SetString(o.PaymentThreshold, 10)
-	lightPaymentThreshold := new(big.Int).Div(paymentThreshold, big.NewInt(lightFactor))
-
-	pricer := pricer.NewFixedPricer(swarmAddress, basePrice)
+}
 
-	pricing := pricing.New(p2ps, logger, paymentThreshold, lightPaymentThreshold, big.NewInt(minPaymentThreshold))
-	if err = p2ps.AddProtocol(pricing.Protocol()); err != nil {
-		return nil, fmt.Errorf("pricing service: %w", err)
+// newChainSnapshotGetter verifies the chain snapshot against its manifest and
+// the signers and returns its events as a snapshot.
+func newChainSnapshotGetter(data []byte, manifest *SnapshotManifest, signers []common.Address) (SnapshotGetter, error) {
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("%w: no trusted signers", ErrSnapshotUntrusted)
 	}
-
-	acc, err := accounting.NewAccounting(
-		paymentThreshold,
-		o.PaymentTolerance,
-		o.PaymentEarly,
-		logger,
-		stateStore,
-		pricing,
-		big.NewInt(refreshRate),
-		lightFactor,
-		p2ps,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("accounting: %w", err)
+	if err := manifest.Verify(signers); err != nil {
+		return nil, err
 	}
-	b.accountingCloser = acc
-
-	// bootstrapper mode uses the light node refresh rate
-	enforcedRefreshRate := big.NewInt(lightRefreshRate)
-
-	pseudosettleService := pseudosettle.New(p2ps, logger, stateStore, acc, enforcedRefreshRate, enforcedRefreshRate, p2ps)
-	if err = p2ps.AddProtocol(pseudosettleService.Protocol()); err != nil {
-		return nil, fmt.Errorf("pseudosettle service: %w", err)
+	sum := sha256.Sum256(data)
+	if err := manifest.checkHash(sum[:]); err != nil {
+		return nil, err
 	}
 
-	acc.SetRefreshFunc(pseudosettleService.Pay)
-
-	pricing.SetPaymentThresholdObserver(acc)
-
-	localStore, err := storer.New(ctx, "", &storer.Options{
-		CacheCapacity: 1_000_000,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("local store creation: %w", err)
+	state := &postage.ChainSnapshot{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("unmarshal chain snapshot: %w", err)
 	}
-	b.localstoreCloser = localStore
-
-	radiusF := func() (uint8, error) { return swarm.MaxBins, nil }
-
-	retrieve := retrieval.New(swarmAddress, radiusF, localStore, p2ps, kad, logger, acc, pricer, tracer, o.RetrievalCaching)
-	if err = p2ps.AddProtocol(retrieve.Protocol()); err != nil {
-		return nil, fmt.Errorf("retrieval service: %w", err)
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gzipWriter)
+	for _, event := range state.Events {
+		if err := encoder.Encode(event); err != nil {
+			return nil, fmt.Errorf("encode chain snapshot: %w", err)
+		}
 	}
-	b.retrievalCloser = retrieve
-
-	localStore.SetRetrievalService(retrieve)
-
-	if err := kad.Start(p2pCtx); err != nil {
-		return nil, err
+	if err := gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("encode chain snapshot: %w", err)
 	}
 
-	if err := p2ps.Ready(); err != nil {
-		return nil, err
-	}
+	snapshotSum := sha256.Sum256(buf.Bytes())
+	return &chainSnapshotGetter{feedSnapshotGetter{
+		data: buf.Bytes(),
+		manifest: &SnapshotManifest{
+			ToBlock:        state.LastBlockNumber,
+			MaxBlockHeight: state.LastBlockNumber,
+			LogCount:       len(state.Events),
+			Hash:           hex.EncodeToString(snapshotSum[:]),
+		},
+	}}, nil
+}
 
+// fetchSnapshotFeed retrieves the latest update of the postage snapshot feed
+// over the network of the node.
+func fetchSnapshotFeed(
+	ctx context.Context,
+	kad *kademlia.Kad,
+	getter storage.Getter,
+	putter storage.Putter,
+	feed swarm.Address,
+	signers []common.Address,
+	logger log.Logger,
+) (SnapshotGetter, error) {
 	if err := waitPeers(kad); err != nil {
 		return nil, errors.New("timed out waiting for kademlia peers")
 	}
 
-	logger.Info("bootstrap: trying to fetch stamps snapshot")
+	logger.Info("bootstrap: trying to fetch feed", "feed", feed)
 
 	var (
 		snapshotRootCh swarm.Chunk
 		reader         file.Joiner
 		l              int64
 		eventsJSON     []byte
+		err            error
 	)
 
 	for i := 0; i < getSnapshotRetries; i++ {
@@ -213,7 +173,7 @@ func bootstrapNode(
 		ctx, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
 
-		snapshotRootCh, err = getLatestSnapshot(ctx, localStore.Download(true), localStore.Cache(), snapshotFeed)
+		snapshotRootCh, err = getLatestSnapshot(ctx, getter, putter, feed)
 		if err != nil {
 			logger.Warning("bootstrap: fetching snapshot failed", "error", err)
 			continue
@@ -232,7 +192,7 @@ func bootstrapNode(
 		ctx, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
 
-		reader, l, err = joiner.NewJoiner(ctx, localStore.Download(true), localStore.Cache(), snapshotRootCh.Address(), snapshotRootCh)
+		reader, l, err = joiner.NewJoiner(ctx, getter, putter, snapshotRootCh.Address(), snapshotRootCh)
 		if err != nil {
 			logger.Warning("bootstrap: file joiner failed", "error", err)
 			continue
@@ -255,28 +215,34 @@ func bootstrapNode(
 		return nil, err
 	}
 
-	events := postage.ChainSnapshot{}
-	err = json.Unmarshal(eventsJSON, &events)
-	if err != nil {
-		return nil, err
-	}
-
-	return &events, nil
+	return parseFeedSnapshot(eventsJSON, signers)
 }
 
 // wait till some peers are connected. returns true if all is ok
 func waitPeers(kad *kademlia.Kad) error {
-	const minPeersCount = 25
-	return spinlock.WaitWithInterval(time.Minute, time.Second, func() bool {
+	return spinlock.WaitWithInterval(feedPeersTimeout, time.Second, func() bool {
 		count := 0
 		_ = kad.EachConnectedPeer(func(_ swarm.Address, _ uint8) (bool, bool, error) {
 			count++
 			return false, false, nil
 		}, topology.Select{})
-		return count >= minPeersCount
+		return count >= minFeedPeers
 	})
 }
 
+// setPostageSource records the source the postage batches are synced from.
+func (b *Bee) setPostageSource(source string, logger log.Logger) {
+	b.postageSource.Store(source)
+	logger.Info("postage batches synced", "source", source)
+}
+
+// postageSyncSource returns the source the postage batches are synced from,
+// empty while it is not decided yet.
+func (b *Bee) postageSyncSource() string {
+	source, _ := b.postageSource.Load().(string)
+	return source
+}
+
 func getLatestSnapshot(
 	ctx context.Context,
 	st storage.Getter,
@@ -349,3 +315,12 @@ func batchStoreExists(s storage.StateStorer) (bool, error) {
 
 	return hasOne, err
 }
+
+func batchStoreDirty(s storage.StateStorer) (bool, error) {
+	dirty := false
+	err := s.Get(batchServiceDirtyKey, &dirty)
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	return dirty, err
+}
@@ -8,18 +8,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"path"
-	"path/filepath"
-	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/feeds"
-	"github.com/ethersphere/bee/v2/pkg/file/joiner"
 	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
 	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
 	"github.com/ethersphere/bee/v2/pkg/manifest"
 	"github.com/ethersphere/bee/v2/pkg/soc"
-	"github.com/ethersphere/bee/v2/pkg/storage"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
 
@@ -136,83 +131,7 @@ func (bl *Beelite) AddFileBzz(parentContext context.Context,
 }
 
 func (bl *Beelite) GetBzz(parentContext context.Context, address swarm.Address, publisher *ecdsa.PublicKey, historyAddress *swarm.Address, timestamp *int64) (io.Reader, string, error) {
-	cache := true
-	ls := loadsave.NewReadonly(bl.storer.Download(cache),bl.storer.Cache(), redundancy.DefaultLevel)
-	feedDereferenced := false
-
-	ctx := parentContext
-	decryptedRef, err := bl.actDecryptionHandler(parentContext, address, publisher, historyAddress, timestamp, cache)
-	if err != nil {
-		bl.logger.Error(err, "act decryption failed")
-		return nil, "", err
-	}
-FETCH:
-	// read manifest entry
-	m, err := manifest.NewDefaultManifestReference(
-		decryptedRef,
-		ls,
-	)
-	if err != nil {
-		bl.logger.Error(err, "bzz download: not manifest", "address", decryptedRef)
-		return nil, "", err
-	}
-
-	// there's a possible ambiguity here, right now the data which was
-	// read can be an entry.Entry or a mantaray feed manifest. Try to
-	// unmarshal as mantaray first and possibly resolve the feed, otherwise
-	// go on normally.
-	if !feedDereferenced {
-		if l, err := bl.manifestFeed(ctx, m); err == nil {
-			//we have a feed manifest here
-			ch, cur, _, err := l.At(ctx, time.Now().Unix(), 0)
-			if err != nil {
-				bl.logger.Error(err, "bzz download: feed lookup failed")
-				return nil, "", err
-			}
-			if ch == nil {
-				bl.logger.Error(err, "bzz download: feed lookup")
-				return nil, "", err
-			}
-			ref, _, err := parseFeedUpdate(ch)
-			if err != nil {
-				bl.logger.Error(err, "bzz download: mapStructure feed update failed")
-				return nil, "", err
-			}
-			decryptedRef = ref
-			feedDereferenced = true
-			curBytes, err := cur.MarshalBinary()
-			if err != nil {
-				bl.logger.Error(err, "bzz download: marshal index failed")
-				return nil, "", err
-			}
-			_ = curBytes
-			goto FETCH
-		}
-	}
-
-	if indexDocumentSuffixKey, ok := manifestMetadataLoad(ctx, m, manifest.RootPath, manifest.WebsiteIndexDocumentSuffixKey); ok {
-		pathWithIndex := path.Join("", indexDocumentSuffixKey)
-		indexDocumentManifestEntry, err := m.Lookup(ctx, pathWithIndex)
-		if err == nil {
-			// index document exists
-			bl.logger.Debug("bzz download: serving path: %s", pathWithIndex)
-			mtdt := indexDocumentManifestEntry.Metadata()
-			fname, ok := mtdt[manifest.EntryMetadataFilenameKey]
-			if ok {
-				fname = filepath.Base(fname) // only keep the file name
-			}
-			reader, _, err := joiner.New(ctx, bl.storer.Download(cache), bl.storer.Cache(), indexDocumentManifestEntry.Reference(), redundancy.DefaultLevel)
-			if err != nil {
-				if errors.Is(err, storage.ErrNotFound) {
-					return nil, "", fmt.Errorf("api download: not found : %w", err)
-				}
-				return nil, "", fmt.Errorf("unexpected error: %s: %v", indexDocumentManifestEntry.Reference(), err)
-			}
-			return reader, fname, nil
-		}
-	}
-
-	return nil, "", fmt.Errorf("failed to get bzz reference")
+	return bl.GetBzzPath(parentContext, address, "", publisher, historyAddress, timestamp)
 }
 
 func (bl *Beelite) manifestFeed(
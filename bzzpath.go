@@ -0,0 +1,230 @@
+package beelite
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/file"
+	"github.com/ethersphere/bee/v2/pkg/file/joiner"
+	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/manifest"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/storer"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// The metadata of a manifest entry protected by its own access control, with
+// the history and publisher needed to decrypt its reference.
+const (
+	actHistoryMetadataKey   = "swarm-act-history-address"
+	actPublisherMetadataKey = "swarm-act-publisher"
+)
+
+var (
+	errBzzPathNotFound   = errors.New("bzz path not found")
+	errEmptyAccessPrefix = errors.New("path access prefix is empty")
+)
+
+// PathAccess protects the files of a directory under Prefix with the access
+// control of the history, e.g. the private section of a website. Different
+// paths can have different grantee lists.
+type PathAccess struct {
+	// Prefix is the path prefix of the protected files, e.g. "private/". It
+	// matches whole path segments, "private" does not match "privateer".
+	Prefix string
+	// HistoryAddress is the access control history of the files, a new one
+	// only accessible by the publisher is created if it is zero.
+	HistoryAddress swarm.Address
+}
+
+// pathAccessFor returns the access of the longest prefix of filePath, nil if
+// the file is not protected.
+func pathAccessFor(access []PathAccess, filePath string) *PathAccess {
+	var match *PathAccess
+	for i := range access {
+		prefix := strings.TrimPrefix(access[i].Prefix, "/")
+		if hasPathPrefix(filePath, prefix) && (match == nil || len(prefix) > len(strings.TrimPrefix(match.Prefix, "/"))) {
+			match = &access[i]
+		}
+	}
+	return match
+}
+
+// hasPathPrefix reports whether prefix is filePath or ends at a segment
+// boundary of it.
+func hasPathPrefix(filePath, prefix string) bool {
+	if !strings.HasPrefix(filePath, prefix) {
+		return false
+	}
+	return strings.HasSuffix(prefix, "/") || len(filePath) == len(prefix) || filePath[len(prefix)] == '/'
+}
+
+// protectEntry encrypts the reference of the file with the access control of
+// the path and returns the metadata to decrypt it with. The history of the
+// access should exist, creating one finishes the session of the putter.
+func (bl *Beelite) protectEntry(ctx context.Context, putter storer.PutterSession, access *PathAccess, reference swarm.Address) (swarm.Address, map[string]string, error) {
+	encryptedReference, historyAddress, err := bl.actEncryptionHandler(ctx, putter, reference, access.HistoryAddress)
+	if err != nil {
+		return swarm.ZeroAddress, nil, err
+	}
+	access.HistoryAddress = historyAddress
+
+	return encryptedReference, map[string]string{
+		actHistoryMetadataKey:   historyAddress.String(),
+		actPublisherMetadataKey: hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(bl.publicKey)),
+	}, nil
+}
+
+// GetBzzPath returns the file at the path of the manifest, or its index
+// document if the path is a directory. The manifest reference is decrypted
+// with the publisher and history if given, the file reference with the
+// access control of its entry if the path is protected on its own.
+func (bl *Beelite) GetBzzPath(ctx context.Context, address swarm.Address, filePath string, publisher *ecdsa.PublicKey, historyAddress *swarm.Address, timestamp *int64) (io.Reader, string, error) {
+	cache := true
+	ls := loadsave.NewReadonly(bl.storer.Download(cache), bl.storer.Cache(), redundancy.DefaultLevel)
+
+	decryptedRef, err := bl.actDecryptionHandler(ctx, address, publisher, historyAddress, timestamp, cache)
+	if err != nil {
+		bl.logger.Error(err, "act decryption failed")
+		return nil, "", err
+	}
+
+	m, err := bl.loadBzzManifest(ctx, ls, decryptedRef)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entry, err := lookupBzzPath(ctx, m, strings.TrimPrefix(filePath, "/"))
+	if err != nil {
+		bl.logger.Debug("bzz download: path not found", "path", filePath, "error", err)
+		return nil, "", err
+	}
+	bl.logger.Debug("bzz download: serving path", "path", filePath, "address", entry.Reference())
+
+	reference := entry.Reference()
+	mtdt := entry.Metadata()
+	if h, ok := mtdt[actHistoryMetadataKey]; ok {
+		reference, err = bl.decryptEntry(ctx, reference, h, mtdt[actPublisherMetadataKey], publisher, timestamp, cache)
+		if err != nil {
+			bl.logger.Error(err, "bzz download: path act decryption failed", "path", filePath)
+			return nil, "", err
+		}
+	}
+
+	fname, ok := mtdt[manifest.EntryMetadataFilenameKey]
+	if ok {
+		fname = filepath.Base(fname) // only keep the file name
+	}
+	reader, _, err := joiner.New(ctx, bl.storer.Download(cache), bl.storer.Cache(), reference, redundancy.DefaultLevel)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, "", fmt.Errorf("api download: not found : %w", err)
+		}
+		return nil, "", fmt.Errorf("unexpected error: %s: %v", reference, err)
+	}
+	return reader, fname, nil
+}
+
+// loadBzzManifest loads the manifest of the reference, or of the latest
+// update of the feed if it is a feed manifest.
+func (bl *Beelite) loadBzzManifest(ctx context.Context, ls file.LoadSaver, reference swarm.Address) (manifest.Interface, error) {
+	m, err := manifest.NewDefaultManifestReference(reference, ls)
+	if err != nil {
+		bl.logger.Error(err, "bzz download: not manifest", "address", reference)
+		return nil, err
+	}
+
+	// there's a possible ambiguity here, right now the data which was
+	// read can be an entry.Entry or a mantaray feed manifest. Try to
+	// unmarshal as mantaray first and possibly resolve the feed, otherwise
+	// go on normally.
+	l, err := bl.manifestFeed(ctx, m)
+	if err != nil {
+		return m, nil
+	}
+	ch, _, _, err := l.At(ctx, time.Now().Unix(), 0)
+	if err != nil {
+		bl.logger.Error(err, "bzz download: feed lookup failed")
+		return nil, err
+	}
+	if ch == nil {
+		err = fmt.Errorf("bzz download: no feed update")
+		bl.logger.Error(err, "bzz download: feed lookup")
+		return nil, err
+	}
+	ref, _, err := parseFeedUpdate(ch)
+	if err != nil {
+		bl.logger.Error(err, "bzz download: mapStructure feed update failed")
+		return nil, err
+	}
+
+	m, err = manifest.NewDefaultManifestReference(ref, ls)
+	if err != nil {
+		bl.logger.Error(err, "bzz download: not manifest", "address", ref)
+		return nil, err
+	}
+	return m, nil
+}
+
+// lookupBzzPath returns the entry of the path, or of the index document of
+// the directory at the path.
+func lookupBzzPath(ctx context.Context, m manifest.Interface, filePath string) (manifest.Entry, error) {
+	if filePath != "" {
+		entry, err := m.Lookup(ctx, filePath)
+		if err == nil && !entry.Reference().IsZero() {
+			return entry, nil
+		}
+		if err != nil && !errors.Is(err, manifest.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	if indexDocumentSuffixKey, ok := manifestMetadataLoad(ctx, m, manifest.RootPath, manifest.WebsiteIndexDocumentSuffixKey); ok {
+		entry, err := m.Lookup(ctx, path.Join(filePath, indexDocumentSuffixKey))
+		if err == nil {
+			return entry, nil
+		}
+	}
+
+	if filePath == "" {
+		return nil, fmt.Errorf("failed to get bzz reference")
+	}
+	return nil, fmt.Errorf("%w: %s", errBzzPathNotFound, filePath)
+}
+
+// decryptEntry decrypts the reference of a manifest entry protected by its
+// own access control. The publisher of the entry is used unless the entry
+// does not have one.
+func (bl *Beelite) decryptEntry(ctx context.Context, reference swarm.Address, historyHex, publisherHex string, publisher *ecdsa.PublicKey, timestamp *int64, cache bool) (swarm.Address, error) {
+	historyAddress, err := swarm.ParseHexAddress(historyHex)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("invalid act history of entry: %w", err)
+	}
+	if publisherHex != "" {
+		b, err := hex.DecodeString(publisherHex)
+		if err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("invalid act publisher of entry: %w", err)
+		}
+		key, err := btcec.ParsePubKey(b)
+		if err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("invalid act publisher of entry: %w", err)
+		}
+		publisher = key.ToECDSA()
+	}
+	if publisher == nil {
+		return swarm.ZeroAddress, errors.New("act publisher of entry missing")
+	}
+
+	return bl.actDecryptionHandler(ctx, reference, publisher, &historyAddress, timestamp, cache)
+}
@@ -0,0 +1,157 @@
+package beelite
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func TestPathAccessFor(t *testing.T) {
+	t.Parallel()
+
+	access := []PathAccess{
+		{Prefix: "private/"},
+		{Prefix: "/members"},
+		{Prefix: "members/admin"},
+		{Prefix: "docs/secret.html"},
+	}
+
+	for _, tc := range []struct {
+		path string
+		want string
+	}{
+		{path: "index.html"},
+		{path: "private/index.html", want: "private/"},
+		{path: "private/a/b.html", want: "private/"},
+		{path: "privateer/index.html"},
+		{path: "private"},
+		{path: "members", want: "/members"},
+		{path: "members/index.html", want: "/members"},
+		{path: "membership/index.html"},
+		{path: "members/admin/index.html", want: "members/admin"},
+		{path: "members/administrator/index.html", want: "/members"},
+		{path: "docs/secret.html", want: "docs/secret.html"},
+		{path: "docs/secret.html.bak"},
+	} {
+		got := pathAccessFor(access, tc.path)
+		switch {
+		case got == nil && tc.want != "":
+			t.Errorf("%s: got no access, want prefix %q", tc.path, tc.want)
+		case got != nil && got.Prefix != tc.want:
+			t.Errorf("%s: got prefix %q, want %q", tc.path, got.Prefix, tc.want)
+		}
+	}
+}
+
+func TestAddDirBzzWithAccessEmptyPrefix(t *testing.T) {
+	t.Parallel()
+
+	bl := &Beelite{}
+	for _, prefix := range []string{"", "/"} {
+		_, _, _, err := bl.AddDirBzzWithAccess(context.Background(), "", contentTypeTar, "", "", false, swarm.ZeroAddress, []PathAccess{{Prefix: prefix}}, false, redundancy.NONE, strings.NewReader(""))
+		if !errors.Is(err, errEmptyAccessPrefix) {
+			t.Errorf("prefix %q: got error %v, want %v", prefix, err, errEmptyAccessPrefix)
+		}
+	}
+}
+
+// testTar returns a tar archive of the files by their paths.
+func testTar(t *testing.T, files map[string]string) io.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for name, content := range files {
+		err := w.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestAddDirBzzWithAccess(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bl, key := newTestUploadBeelite(t)
+	batch := hex.EncodeToString(make([]byte, 32))
+
+	files := map[string]string{
+		"index.html":         "public index",
+		"public/about.html":  "public about",
+		"private/index.html": "private index",
+		"private/a/b.html":   "private file",
+		"privateer.html":     "not private",
+	}
+	ref, history, access, err := bl.AddDirBzzWithAccess(ctx, batch, contentTypeTar, "index.html", "", true, swarm.ZeroAddress, []PathAccess{{Prefix: "private/"}}, false, redundancy.NONE, testTar(t, files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(access) != 1 || access[0].HistoryAddress.IsZero() {
+		t.Fatalf("got access %+v, want a history for private/", access)
+	}
+	// the returned reference is encrypted with the history
+	if _, _, err := bl.GetBzzPath(ctx, ref, "", nil, nil, nil); err == nil {
+		t.Fatal("got manifest of the encrypted reference")
+	}
+	manifestRef, err := bl.actDecryptionHandler(ctx, ref, &key.PublicKey, &history, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := bl.loadBzzManifest(ctx, loadsave.NewReadonly(bl.storer.Download(true), bl.storer.Cache(), redundancy.DefaultLevel), manifestRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path      string
+		want      string
+		protected bool
+	}{
+		{path: "", want: "public index"},
+		{path: "public/about.html", want: "public about"},
+		{path: "privateer.html", want: "not private"},
+		{path: "private", want: "private index", protected: true},
+		{path: "private/a/b.html", want: "private file", protected: true},
+	} {
+		reader, _, err := bl.GetBzzPath(ctx, ref, tc.path, &key.PublicKey, &history, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Fatalf("%s: got content %q, want %q", tc.path, got, tc.want)
+		}
+
+		entry, err := lookupBzzPath(ctx, m, tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, protected := entry.Metadata()[actHistoryMetadataKey]
+		if protected != tc.protected {
+			t.Fatalf("%s: got protected %t, want %t", tc.path, protected, tc.protected)
+		}
+		if protected && h != access[0].HistoryAddress.String() {
+			t.Fatalf("%s: got history %s, want %s", tc.path, h, access[0].HistoryAddress)
+		}
+	}
+}
@@ -28,7 +28,7 @@ func (bl *Beelite) GetChunk(parentContext context.Context, reference swarm.Addre
 		if errors.Is(err, storage.ErrNotFound) {
 			msg := fmt.Sprintf("chunk: chunk not found. addr %s", decryptedRef)
 			bl.logger.Debug(msg)
-			return nil, fmt.Errorf(msg)
+			return nil, errors.New(msg)
 
 		}
 		return nil, fmt.Errorf("chunk: chunk read error: %v ,addr %s", err, decryptedRef)
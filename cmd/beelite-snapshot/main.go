@@ -0,0 +1,259 @@
+// Command beelite-snapshot writes a postage snapshot for bee-lite from the
+// events of the postage contract, read from a blockchain endpoint or from a
+// local log dump, together with a manifest holding its hash and signature.
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	beelite "github.com/Solar-Punk-Ltd/bee-lite"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/postage/listener"
+)
+
+const passwordEnv = "BEELITE_SNAPSHOT_PASSWORD"
+
+type config struct {
+	rpc          string
+	logs         string
+	chainID      int64
+	contract     string
+	from         uint64
+	to           uint64
+	after        string
+	blockRange   uint64
+	out          string
+	manifest     string
+	feed         string
+	key          string
+	passwordFile string
+}
+
+func main() {
+	var c config
+	flag.StringVar(&c.rpc, "rpc", "", "blockchain RPC endpoint to read the events from")
+	flag.StringVar(&c.logs, "logs", "", "gzip compressed NDJSON log dump to read the events from instead of -rpc")
+	flag.Int64Var(&c.chainID, "chain-id", chaincfg.Mainnet.ChainID, "chain of the postage contract")
+	flag.StringVar(&c.contract, "contract", "", "postage contract address, the one of the chain if empty")
+	flag.Uint64Var(&c.from, "from", 0, "first block, the postage contract start block of the chain if 0")
+	flag.Uint64Var(&c.to, "to", 0, "last block, the current block if 0")
+	flag.StringVar(&c.after, "after", "", "manifest of the snapshot to write a delta of, starting after its last block, or archive for the snapshot compiled into bee-lite")
+	flag.Uint64Var(&c.blockRange, "range", beelite.DefaultSnapshotBlockRange, "number of blocks requested at once")
+	flag.StringVar(&c.out, "out", "", "snapshot file to write")
+	flag.StringVar(&c.manifest, "manifest", "", "manifest file to write, the snapshot file with .json appended if empty")
+	flag.StringVar(&c.feed, "feed", "", "file to write the snapshot with its manifest to, the content of a postage snapshot feed update")
+	flag.StringVar(&c.key, "key", "", "V3 JSON key file to sign the snapshot with")
+	flag.StringVar(&c.passwordFile, "password-file", "", "file with the password of the key, read from "+passwordEnv+" if empty")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx, c); err != nil {
+		fmt.Fprintln(os.Stderr, "beelite-snapshot:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, c config) error {
+	if c.out == "" {
+		return errors.New("-out not provided")
+	}
+	if (c.rpc == "") == (c.logs == "") {
+		return errors.New("exactly one of -rpc and -logs has to be provided")
+	}
+
+	o := beelite.SnapshotOptions{
+		FromBlock:  c.from,
+		ToBlock:    c.to,
+		BlockRange: c.blockRange,
+	}
+	chainCfg, found := chaincfg.GetByChainID(c.chainID)
+	switch {
+	case c.contract != "":
+		if !common.IsHexAddress(c.contract) {
+			return fmt.Errorf("malformed contract address %q", c.contract)
+		}
+		o.Contract = common.HexToAddress(c.contract)
+	case found:
+		o.Contract = chainCfg.PostageStampAddress
+	default:
+		return fmt.Errorf("unknown chain id %d, -contract has to be provided", c.chainID)
+	}
+	if found {
+		o.ContractABI = chainCfg.PostageStampABI
+		if o.FromBlock == 0 {
+			o.FromBlock = chainCfg.PostageStampStartBlock
+		}
+	}
+
+	if c.after != "" {
+		if c.from != 0 {
+			return errors.New("only one of -from and -after can be provided")
+		}
+		from, err := deltaStart(c.after)
+		if err != nil {
+			return err
+		}
+		o.FromBlock = from
+	}
+
+	var signer crypto.Signer
+	if c.key != "" {
+		s, err := loadSigner(c.key, c.passwordFile)
+		if err != nil {
+			return err
+		}
+		signer = s
+	}
+
+	var filterer listener.BlockHeightContractFilterer
+	if c.rpc != "" {
+		client, err := ethclient.DialContext(ctx, c.rpc)
+		if err != nil {
+			return fmt.Errorf("dial blockchain client: %w", err)
+		}
+		defer client.Close()
+		filterer = client
+	} else {
+		f := beelite.NewSnapshotLogFilterer(log.Noop, logDumpGetter(c.logs))
+		defer f.Close()
+		filterer = f
+	}
+
+	file, err := os.Create(c.out)
+	if err != nil {
+		return err
+	}
+	m, err := beelite.WriteSnapshot(ctx, filterer, o, file)
+	if err != nil {
+		file.Close()
+		os.Remove(c.out)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if signer != nil {
+		if err := m.Sign(signer); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(os.Stderr, "beelite-snapshot: no -key provided, the snapshot is not signed")
+	}
+
+	manifest := c.manifest
+	if manifest == "" {
+		manifest = c.out + ".json"
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(manifest, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	if c.feed != "" {
+		if err := writeFeedSnapshot(c.feed, c.out, m); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("wrote %d events up to block %d to %s, sha256 %s\n", m.LogCount, m.MaxBlockHeight, c.out, m.Hash)
+	return nil
+}
+
+// deltaStart returns the block after the one the snapshot of the manifest
+// ends with.
+func deltaStart(manifestFile string) (uint64, error) {
+	if manifestFile == "archive" {
+		return beelite.ArchiveSnapshotBlockHeight + 1, nil
+	}
+	data, err := os.ReadFile(manifestFile)
+	if err != nil {
+		return 0, err
+	}
+	var m beelite.SnapshotManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return 0, fmt.Errorf("unmarshal manifest %s: %w", manifestFile, err)
+	}
+	if m.ToBlock != 0 {
+		return m.ToBlock + 1, nil
+	}
+	return m.MaxBlockHeight + 1, nil
+}
+
+func loadSigner(keyFile, passwordFile string) (crypto.Signer, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	password := os.Getenv(passwordEnv)
+	if passwordFile != "" {
+		p, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return nil, err
+		}
+		password = strings.TrimSpace(string(p))
+	}
+
+	key, err := keystore.DecryptKey(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt key: %w", err)
+	}
+	return crypto.NewDefaultSigner(key.PrivateKey), nil
+}
+
+// writeFeedSnapshot writes the snapshot file with its manifest in the format
+// of the postage snapshot feed.
+func writeFeedSnapshot(path, snapshotFile string, m *beelite.SnapshotManifest) error {
+	snapshot, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(beelite.FeedSnapshot{Manifest: m, Snapshot: snapshot})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// logDumpGetter reads a local log dump. The dump is not signed, its manifest
+// only holds the hash of the file, so that the snapshot filterer reads it
+// without trusted signers.
+type logDumpGetter string
+
+func (path logDumpGetter) GetBatchSnapshot(context.Context) ([]byte, error) {
+	return os.ReadFile(string(path))
+}
+
+func (path logDumpGetter) GetSnapshotManifest(context.Context) (*beelite.SnapshotManifest, error) {
+	file, err := os.Open(string(path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return nil, err
+	}
+	return &beelite.SnapshotManifest{Hash: hex.EncodeToString(sum.Sum(nil))}, nil
+}
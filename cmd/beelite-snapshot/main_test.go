@@ -0,0 +1,75 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	beelite "github.com/Solar-Punk-Ltd/bee-lite"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
+	"github.com/ethersphere/bee/v2/pkg/util/abiutil"
+)
+
+func TestRunLogs(t *testing.T) {
+	t.Parallel()
+
+	contract := common.HexToAddress("0x45a1502382541cd610cc9068e88727426b696293")
+	created := abiutil.MustParseABI(chaincfg.Mainnet.PostageStampABI).Events["BatchCreated"].ID
+
+	dir := t.TempDir()
+	dump := filepath.Join(dir, "dump.gz")
+	file, err := os.Create(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzipWriter := gzip.NewWriter(file)
+	encoder := json.NewEncoder(gzipWriter)
+	for block := uint64(1); block <= 40; block++ {
+		// an event of another contract, which is not written to the snapshot
+		address := contract
+		if block%10 == 0 {
+			address = common.HexToAddress("0x01")
+		}
+		err := encoder.Encode(types.Log{Address: address, Topics: []common.Hash{created}, BlockNumber: block})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out.gz")
+	err = run(context.Background(), config{
+		logs:       dump,
+		chainID:    chaincfg.Mainnet.ChainID,
+		contract:   contract.Hex(),
+		from:       1,
+		to:         30,
+		blockRange: beelite.DefaultSnapshotBlockRange,
+		out:        out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m beelite.SnapshotManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.FromBlock != 1 || m.ToBlock != 30 || m.MaxBlockHeight != 29 || m.LogCount != 27 {
+		t.Fatalf("got manifest %+v, want 27 logs of blocks 1 to 30", m)
+	}
+}
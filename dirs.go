@@ -7,10 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"mime"
 	"mime/multipart"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -19,6 +21,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/manifest"
 	storage "github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/storer"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
 
@@ -34,6 +37,9 @@ const (
 	ContentLengthHeader = "Content-Length"
 )
 
+// AddDirBzz uploads a directory given as a tar or multipart body. With act the
+// returned reference is the manifest reference encrypted with the access
+// control of the history, like the one returned by AddBytes.
 func (bl *Beelite) AddDirBzz(
 	parentContext context.Context,
 	batchHex,
@@ -47,7 +53,34 @@ func (bl *Beelite) AddDirBzz(
 	rLevel redundancy.Level,
 	reader io.Reader,
 ) (reference swarm.Address, newHistoryAddress swarm.Address, err error) {
+	reference, newHistoryAddress, _, err = bl.AddDirBzzWithAccess(parentContext, batchHex, contentType, indexFilename, errorFilename, act, historyAddress, nil, encrypt, rLevel, reader)
+	return
+}
+
+// AddDirBzzWithAccess uploads a directory like AddDirBzz and protects the
+// files under the prefixes of access with their own access control, so that
+// one website can have public and private sections. It returns the access
+// with the histories the files were added to.
+func (bl *Beelite) AddDirBzzWithAccess(
+	parentContext context.Context,
+	batchHex,
+	contentType,
+	indexFilename,
+	errorFilename string,
+	act bool,
+	historyAddress swarm.Address,
+	access []PathAccess,
+	encrypt bool,
+	rLevel redundancy.Level,
+	reader io.Reader,
+) (reference swarm.Address, newHistoryAddress swarm.Address, pathAccess []PathAccess, err error) {
 	reference = swarm.ZeroAddress
+	for _, a := range access {
+		if strings.Trim(a.Prefix, "/") == "" {
+			err = errEmptyAccessPrefix
+			return
+		}
+	}
 	mediaType, params, err := mime.ParseMediaType(contentType)
 	if err != nil {
 		err = fmt.Errorf("content type parse failed: %w", err)
@@ -76,6 +109,13 @@ func (bl *Beelite) AddDirBzz(
 		return
 	}
 
+	// a new history is stored in a session of its own before the upload, as
+	// storing it finishes the session
+	pathAccess = slices.Clone(access)
+	if err = bl.createPathHistories(parentContext, batchID, pathAccess); err != nil {
+		return
+	}
+
 	var (
 		tag      uint64
 		deferred = false
@@ -100,7 +140,7 @@ func (bl *Beelite) AddDirBzz(
 		return
 	}
 
-	reference, err = bl.storeDir(
+	manifestReference, err := bl.storeDir(
 		parentContext,
 		encrypt,
 		dReader,
@@ -109,43 +149,65 @@ func (bl *Beelite) AddDirBzz(
 		indexFilename,
 		errorFilename,
 		rLevel,
+		pathAccess,
 	)
 	if err != nil {
 		err = fmt.Errorf("store dir failed 1: %w", err)
 		return
 	}
 
-	encryptedReference := reference
+	reference = manifestReference
 	if act {
-		reference, newHistoryAddress, err = bl.actEncryptionHandler(parentContext, putter, reference, historyAddress)
+		reference, newHistoryAddress, err = bl.actEncryptionHandler(parentContext, putter, manifestReference, historyAddress)
 		if err != nil {
 			bl.logger.Error(err, "access control upload failed")
 			return
 		}
 	}
 
-	err = putter.Done(reference)
+	err = putter.Done(manifestReference)
 	if err != nil {
 		bl.logger.Error(err, "store dir failed")
 		err = errors.Join(fmt.Errorf("store dir failed 2: %w", err), putter.Cleanup())
 		return
 	}
 
-	reference = encryptedReference
 	return
 }
 
+// createPathHistories creates the histories of the access without one.
+func (bl *Beelite) createPathHistories(ctx context.Context, batchID []byte, access []PathAccess) error {
+	for i := range access {
+		if !access[i].HistoryAddress.IsZero() {
+			continue
+		}
+		putter, err := bl.newStamperPutter(ctx, putterOptions{BatchID: batchID})
+		if err != nil {
+			return fmt.Errorf("get putter failed: %w", err)
+		}
+		// the history is created with the first reference it encrypts
+		_, historyAddress, err := bl.actEncryptionHandler(ctx, putter, swarm.ZeroAddress, swarm.ZeroAddress)
+		if err != nil {
+			return errors.Join(fmt.Errorf("create history of %s: %w", access[i].Prefix, err), putter.Cleanup())
+		}
+		access[i].HistoryAddress = historyAddress
+	}
+	return nil
+}
+
 // storeDir stores all files recursively contained in the directory given as a tar/multipart
 // it returns the hash for the uploaded manifest corresponding to the uploaded dir
+// the references of the files under the prefixes of access are encrypted with their access control
 func (bl *Beelite) storeDir(
 	ctx context.Context,
 	encrypt bool,
 	reader dirReader,
-	putter storage.Putter,
+	putter storer.PutterSession,
 	getter storage.Getter,
 	indexFilename,
 	errorFilename string,
 	rLevel redundancy.Level,
+	access []PathAccess,
 ) (swarm.Address, error) {
 	p := requestPipelineFn(putter, encrypt, rLevel)
 	factory := requestPipelineFactory(ctx, putter, encrypt, rLevel)
@@ -181,6 +243,14 @@ func (bl *Beelite) storeDir(
 			manifest.EntryMetadataContentTypeKey: fileInfo.ContentType,
 			manifest.EntryMetadataFilenameKey:    fileInfo.Name,
 		}
+		if a := pathAccessFor(access, fileInfo.Path); a != nil {
+			var actMtdt map[string]string
+			fileReference, actMtdt, err = bl.protectEntry(ctx, putter, a, fileReference)
+			if err != nil {
+				return swarm.ZeroAddress, fmt.Errorf("access control of %s: %w", fileInfo.Path, err)
+			}
+			maps.Copy(fileMtdt, actMtdt)
+		}
 		// add file entry to dir manifest
 		err = dirManifest.Add(ctx, fileInfo.Path, manifest.NewEntry(fileReference, fileMtdt))
 		if err != nil {
@@ -0,0 +1,352 @@
+package beelite
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/postage"
+	storer "github.com/ethersphere/bee/v2/pkg/storer"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/topology"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+)
+
+const (
+	eventBufferSize       = 64
+	tagSyncPollInterval   = 5 * time.Second
+	chainSyncPollInterval = 10 * time.Second
+	tagListPageSize       = 100
+)
+
+type EventKind int
+
+const (
+	EventPeerConnected EventKind = iota + 1
+	EventPeerDisconnected
+	EventStorageRadiusChanged
+	EventBatchCreated
+	EventBatchExpired
+	EventTagSynced
+	EventChainSyncProgress
+	EventWarmupFinished
+	EventShutdown
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventPeerConnected:
+		return "peer-connected"
+	case EventPeerDisconnected:
+		return "peer-disconnected"
+	case EventStorageRadiusChanged:
+		return "storage-radius-changed"
+	case EventBatchCreated:
+		return "batch-created"
+	case EventBatchExpired:
+		return "batch-expired"
+	case EventTagSynced:
+		return "tag-synced"
+	case EventChainSyncProgress:
+		return "chain-sync-progress"
+	case EventWarmupFinished:
+		return "warmup-finished"
+	case EventShutdown:
+		return "shutdown"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is emitted on the channels returned by Beelite.Subscribe. Only the
+// fields relevant to the Kind are set.
+type Event struct {
+	Kind EventKind
+	Time time.Time
+
+	// Peer is set for EventPeerConnected and EventPeerDisconnected.
+	Peer swarm.Address
+	// Radius is set for EventStorageRadiusChanged.
+	Radius uint8
+	// BatchID is set for EventBatchCreated and EventBatchExpired.
+	BatchID []byte
+	// TagID and Reference are set for EventTagSynced.
+	TagID     uint64
+	Reference swarm.Address
+	// Block, ChainHead and Synced are set for EventChainSyncProgress.
+	Block     uint64
+	ChainHead uint64
+	Synced    bool
+}
+
+type eventSubscription struct {
+	c       chan Event
+	kinds   map[EventKind]struct{}
+	dropped uint64
+}
+
+func (s *eventSubscription) wants(kind EventKind) bool {
+	if len(s.kinds) == 0 {
+		return true
+	}
+	_, ok := s.kinds[kind]
+	return ok
+}
+
+// eventBus fans node events out to subscribers. Every subscriber has a bounded
+// buffer, events that do not fit are dropped and counted.
+type eventBus struct {
+	mu     sync.Mutex
+	subs   map[<-chan Event]*eventSubscription
+	closed bool
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{
+		subs: make(map[<-chan Event]*eventSubscription),
+	}
+}
+
+func (b *eventBus) subscribe(kinds ...EventKind) <-chan Event {
+	s := &eventSubscription{
+		c:     make(chan Event, eventBufferSize),
+		kinds: make(map[EventKind]struct{}, len(kinds)),
+	}
+	for _, k := range kinds {
+		s.kinds[k] = struct{}{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(s.c)
+		return s.c
+	}
+	b.subs[s.c] = s
+	return s.c
+}
+
+func (b *eventBus) unsubscribe(c <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if s, ok := b.subs[c]; ok {
+		delete(b.subs, c)
+		close(s.c)
+	}
+}
+
+func (b *eventBus) dropped(c <-chan Event) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if s, ok := b.subs[c]; ok {
+		return s.dropped
+	}
+	return 0
+}
+
+// hasSubscribers reports whether anyone listens for the given kind, so that
+// producers which need to poll can skip the work otherwise.
+func (b *eventBus) hasSubscribers(kind EventKind) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, s := range b.subs {
+		if s.wants(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *eventBus) publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	for _, s := range b.subs {
+		if !s.wants(e.Kind) {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+			s.dropped++
+		}
+	}
+}
+
+// close publishes the shutdown event and closes all subscriber channels.
+func (b *eventBus) close() {
+	b.publish(Event{Kind: EventShutdown})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for c, s := range b.subs {
+		delete(b.subs, c)
+		close(s.c)
+	}
+}
+
+// watchPeers diffs the connected peer set on every topology change and emits
+// connect and disconnect events.
+func (b *eventBus) watchPeers(ctx context.Context, topologyDriver topology.Driver, p2ps p2p.Service) {
+	c, unsubscribe := topologyDriver.SubscribeTopologyChange()
+	defer unsubscribe()
+
+	connected := make(map[string]swarm.Address)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c:
+		}
+
+		current := make(map[string]swarm.Address)
+		for _, p := range p2ps.Peers() {
+			current[p.Address.ByteString()] = p.Address
+			if _, ok := connected[p.Address.ByteString()]; !ok {
+				b.publish(Event{Kind: EventPeerConnected, Peer: p.Address})
+			}
+		}
+		for k, addr := range connected {
+			if _, ok := current[k]; !ok {
+				b.publish(Event{Kind: EventPeerDisconnected, Peer: addr})
+			}
+		}
+		connected = current
+	}
+}
+
+// watchTags polls the upload sessions and emits an event once for every tag
+// whose chunks have all been synced to the network.
+func (b *eventBus) watchTags(ctx context.Context, uploads storer.UploadStore) {
+	ticker := time.NewTicker(tagSyncPollInterval)
+	defer ticker.Stop()
+
+	reported := make(map[uint64]struct{})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !b.hasSubscribers(EventTagSynced) {
+			continue
+		}
+
+		for offset := 0; ; offset += tagListPageSize {
+			tags, err := uploads.ListSessions(offset, tagListPageSize)
+			if err != nil || len(tags) == 0 {
+				break
+			}
+			for _, t := range tags {
+				if _, ok := reported[t.TagID]; ok {
+					continue
+				}
+				if t.Split == 0 || t.Synced+t.Seen < t.Split {
+					continue
+				}
+				reported[t.TagID] = struct{}{}
+				b.publish(Event{Kind: EventTagSynced, TagID: t.TagID, Reference: t.Address})
+			}
+			if len(tags) < tagListPageSize {
+				break
+			}
+		}
+	}
+}
+
+// watchChainSync reports the postage listener progress against the chain head
+// until syncStatus reports that the initial sync is done.
+func (b *eventBus) watchChainSync(ctx context.Context, backend transaction.Backend, chainState postage.ChainStateGetter, syncStatus func() (bool, error)) {
+	ticker := time.NewTicker(chainSyncPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		done, err := syncStatus()
+		e := Event{Kind: EventChainSyncProgress, Synced: done && err == nil}
+		if cs := chainState.GetChainState(); cs != nil {
+			e.Block = cs.Block
+		}
+		if head, err := backend.BlockNumber(ctx); err == nil {
+			e.ChainHead = head
+		}
+		b.publish(e)
+
+		if done {
+			return
+		}
+	}
+}
+
+// batchEventNotifier forwards the batch events of our own batches to the
+// postage service and publishes them on the event bus.
+type batchEventNotifier struct {
+	postage.BatchEventListener
+	bus *eventBus
+}
+
+func (n *batchEventNotifier) HandleCreate(b *postage.Batch, amount *big.Int) error {
+	if err := n.BatchEventListener.HandleCreate(b, amount); err != nil {
+		return err
+	}
+	n.bus.publish(Event{Kind: EventBatchCreated, BatchID: b.ID})
+	return nil
+}
+
+// batchExpiryNotifier forwards batch expiry to the postage service and
+// publishes it on the event bus.
+type batchExpiryNotifier struct {
+	postage.BatchExpiryHandler
+	bus *eventBus
+}
+
+func (n *batchExpiryNotifier) HandleStampExpiry(ctx context.Context, id []byte) error {
+	if err := n.BatchExpiryHandler.HandleStampExpiry(ctx, id); err != nil {
+		return err
+	}
+	n.bus.publish(Event{Kind: EventBatchExpired, BatchID: id})
+	return nil
+}
+
+// Subscribe returns a channel receiving node events of the given kinds, or of
+// all kinds if none is given. The channel is buffered, events are dropped if
+// the subscriber does not keep up (see DroppedEvents). The channel is closed
+// on Unsubscribe or after the EventShutdown event.
+func (bl *Beelite) Subscribe(kinds ...EventKind) <-chan Event {
+	return bl.bee.events.subscribe(kinds...)
+}
+
+// Unsubscribe stops delivery of events to the channel and closes it.
+func (bl *Beelite) Unsubscribe(c <-chan Event) {
+	bl.bee.events.unsubscribe(c)
+}
+
+// DroppedEvents returns the number of events dropped for the subscription
+// because its buffer was full.
+func (bl *Beelite) DroppedEvents(c <-chan Event) uint64 {
+	return bl.bee.events.dropped(c)
+}
@@ -1,18 +1,28 @@
 package beelite
 
 import (
+	"cmp"
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
+	"math"
+	"slices"
+	"strconv"
 
-	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
 	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
 	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/manifest"
+	"github.com/ethersphere/bee/v2/pkg/manifest/mantaray"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
 
+// encryptedGranteeListKey is the metadata key of the encrypted grantee list
+// reference in the entries of the access control history.
+const encryptedGranteeListKey = "encryptedglref"
+
 func (bl *Beelite) GetGranteeList(ctx context.Context, encryptedglRef swarm.Address, cache bool) ([]string, error) {
 	publisher := bl.publicKey
 	ls := loadsave.NewReadonly(bl.storer.Download(cache), bl.storer.Cache(), redundancy.DefaultLevel)
@@ -21,12 +31,134 @@ func (bl *Beelite) GetGranteeList(ctx context.Context, encryptedglRef swarm.Addr
 		bl.logger.Error(err, "could not get grantees")
 		return nil, err
 	}
-	granteeSlice := make([]string, len(grantees))
-	for i, grantee := range grantees {
-		granteeSlice[i] = hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(grantee))
+	return encodeKeys(grantees), nil
+}
+
+// ActHistoryEntry is a version of the access control of a history, valid from
+// its timestamp until the one of the next entry.
+type ActHistoryEntry struct {
+	// Timestamp is the unix time the entry was created at.
+	Timestamp int64
+	// ActReference is the hex reference of the access control trie.
+	ActReference string
+	// EncryptedGranteeListReference is the hex reference of the encrypted
+	// grantee list, empty for the entry of an upload without grantees.
+	EncryptedGranteeListReference string
+}
+
+// ListActHistory returns the entries of the access control history, oldest
+// first.
+func (bl *Beelite) ListActHistory(ctx context.Context, historyRef swarm.Address) ([]ActHistoryEntry, error) {
+	ls := loadsave.NewReadonly(bl.storer.Download(true), bl.storer.Cache(), redundancy.DefaultLevel)
+	m, err := manifest.NewMantarayManifestReference(historyRef, ls)
+	if err != nil {
+		bl.logger.Error(err, "could not load act history")
+		return nil, err
+	}
+	root, ok := m.(interface{ Root() *mantaray.Node })
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", accesscontrol.ErrUnexpectedType, m)
+	}
+
+	var entries []ActHistoryEntry
+	err = root.Root().WalkNode(ctx, []byte{}, ls, func(path []byte, node *mantaray.Node, err error) error {
+		if err != nil {
+			return err
+		}
+		if !node.IsValueType() || len(node.Entry()) == 0 {
+			return nil
+		}
+		// the history keys are the timestamps subtracted from MaxInt64
+		reversed, err := strconv.ParseInt(string(path), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid act history key %q: %w", path, err)
+		}
+		entries = append(entries, ActHistoryEntry{
+			Timestamp:                     math.MaxInt64 - reversed,
+			ActReference:                  swarm.NewAddress(node.Entry()).String(),
+			EncryptedGranteeListReference: node.Metadata()[encryptedGranteeListKey],
+		})
+		return nil
+	})
+	if err != nil {
+		bl.logger.Error(err, "could not walk act history")
+		return nil, err
+	}
+
+	slices.SortFunc(entries, func(a, b ActHistoryEntry) int {
+		return cmp.Compare(a.Timestamp, b.Timestamp)
+	})
+	return entries, nil
+}
+
+// ActHistory is the access control history returned by GetActHistory, with
+// accessors to its entries as gomobile cannot export slices of structs.
+type ActHistory struct {
+	entries []ActHistoryEntry
+}
+
+// Len returns the number of entries of the history.
+func (h *ActHistory) Len() int {
+	return len(h.entries)
+}
+
+// Entry returns the entry at index i, oldest first.
+func (h *ActHistory) Entry(i int) (*ActHistoryEntry, error) {
+	if i < 0 || i >= len(h.entries) {
+		return nil, fmt.Errorf("act history entry %d out of range of %d entries", i, len(h.entries))
+	}
+	entry := h.entries[i]
+	return &entry, nil
+}
+
+// GetActHistory returns the entries of the access control history like
+// ListActHistory, for gomobile.
+func (bl *Beelite) GetActHistory(ctx context.Context, historyRef swarm.Address) (*ActHistory, error) {
+	entries, err := bl.ListActHistory(ctx, historyRef)
+	if err != nil {
+		return nil, err
+	}
+	return &ActHistory{entries: entries}, nil
+}
+
+// GetGranteeListAt returns the grantees of the history at the unix timestamp,
+// the ones of the latest entry created at or before it.
+func (bl *Beelite) GetGranteeListAt(ctx context.Context, historyRef swarm.Address, timestamp int64) ([]string, error) {
+	if timestamp <= 0 {
+		return nil, accesscontrol.ErrInvalidTimestamp
+	}
+
+	entries, err := bl.ListActHistory(ctx, historyRef)
+	if err != nil {
+		return nil, err
+	}
+	i, found := slices.BinarySearchFunc(entries, timestamp, func(e ActHistoryEntry, ts int64) int {
+		return cmp.Compare(e.Timestamp, ts)
+	})
+	if found {
+		i++
+	}
+	if i == 0 {
+		return nil, fmt.Errorf("%w: no act history entry at or before %d", accesscontrol.ErrNotFound, timestamp)
 	}
 
-	return granteeSlice, nil
+	entry := entries[i-1]
+	if entry.EncryptedGranteeListReference == "" {
+		return []string{}, nil
+	}
+	encryptedglRef, err := swarm.ParseHexAddress(entry.EncryptedGranteeListReference)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grantee list reference: %w", err)
+	}
+	return bl.GetGranteeList(ctx, encryptedglRef, true)
+}
+
+func encodeKeys(keys []*ecdsa.PublicKey) []string {
+	encoded := make([]string, len(keys))
+	for i, key := range keys {
+		encoded[i] = hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(key))
+	}
+	return encoded
 }
 
 func (bl *Beelite) AddRevokeGrantees(ctx context.Context, batchHex string, granteesAddress swarm.Address, historyAddress swarm.Address, addlist, revokelist []string) (swarm.Address, swarm.Address, error) {
@@ -51,13 +183,13 @@ func (bl *Beelite) AddRevokeGrantees(ctx context.Context, batchHex string, grant
 		}
 	}
 
-	parsedAddlist, err := parseKeys(addlist)
+	parsedAddlist, err := bl.granteeKeys(ctx, addlist)
 	if err != nil {
 		bl.logger.Error(err, "add list key parse failed")
 		return swarm.ZeroAddress, swarm.ZeroAddress, err
 	}
 
-	parsedRevokelist, err := parseKeys(revokelist)
+	parsedRevokelist, err := bl.granteeKeys(ctx, revokelist)
 	if err != nil {
 		bl.logger.Error(err, "revoke list key parse failed")
 		return swarm.ZeroAddress, swarm.ZeroAddress, err
@@ -138,7 +270,7 @@ func (bl *Beelite) CreateGrantees(ctx context.Context, batchHex string, historyA
 		return swarm.ZeroAddress, swarm.ZeroAddress, err
 	}
 
-	list, err := parseKeys(granteeList)
+	list, err := bl.granteeKeys(ctx, granteeList)
 	if err != nil {
 		bl.logger.Error(nil, "create list key parse failed")
 		return swarm.ZeroAddress, swarm.ZeroAddress, err
@@ -173,20 +305,3 @@ func (bl *Beelite) CreateGrantees(ctx context.Context, batchHex string, historyA
 
 	return encryptedglref, historyref, nil
 }
-
-func parseKeys(list []string) ([]*ecdsa.PublicKey, error) {
-	parsedList := make([]*ecdsa.PublicKey, 0, len(list))
-	for _, g := range list {
-		h, err := hex.DecodeString(g)
-		if err != nil {
-			return []*ecdsa.PublicKey{}, fmt.Errorf("failed to decode grantee: %w", err)
-		}
-		k, err := btcec.ParsePubKey(h)
-		if err != nil {
-			return []*ecdsa.PublicKey{}, fmt.Errorf("failed to parse grantee public key: %w", err)
-		}
-		parsedList = append(parsedList, k.ToECDSA())
-	}
-
-	return parsedList, nil
-}
@@ -0,0 +1,102 @@
+package beelite
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func TestGranteeListAt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bl, _ := newTestUploadBeelite(t)
+	batch := hex.EncodeToString(make([]byte, 32))
+	grantee1, grantee2 := testGrantee(t), testGrantee(t)
+
+	// the entry of the upload has no grantee list
+	_, history, err := bl.AddBytes(ctx, batch, true, swarm.ZeroAddress, false, redundancy.NONE, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextHistorySecond()
+	granteeRef, history, err := bl.CreateGrantees(ctx, batch, history, []string{grantee1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextHistorySecond()
+	_, history, err = bl.AddRevokeGrantees(ctx, batch, granteeRef, history, []string{grantee2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := bl.ListActHistory(ctx, history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d act history entries, want 3", len(entries))
+	}
+	for i, entry := range entries {
+		if i > 0 && entry.Timestamp <= entries[i-1].Timestamp {
+			t.Fatalf("act history entries not sorted by timestamp: %+v", entries)
+		}
+		if entry.ActReference == "" {
+			t.Fatalf("act history entry %d without act reference", i)
+		}
+		if withList := entry.EncryptedGranteeListReference != ""; withList != (i > 0) {
+			t.Fatalf("act history entry %d has grantee list reference %q", i, entry.EncryptedGranteeListReference)
+		}
+	}
+
+	actHistory, err := bl.GetActHistory(ctx, history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actHistory.Len() != len(entries) {
+		t.Fatalf("got %d entries of act history, want %d", actHistory.Len(), len(entries))
+	}
+	for i, want := range entries {
+		entry, err := actHistory.Entry(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *entry != want {
+			t.Fatalf("got act history entry %+v, want %+v", *entry, want)
+		}
+	}
+	if _, err := actHistory.Entry(len(entries)); err == nil {
+		t.Fatal("got act history entry out of range")
+	}
+
+	if _, err := bl.GetGranteeListAt(ctx, history, entries[0].Timestamp-1); !errors.Is(err, accesscontrol.ErrNotFound) {
+		t.Fatalf("got error %v before the first entry, want %v", err, accesscontrol.ErrNotFound)
+	}
+	for _, tc := range []struct {
+		name      string
+		timestamp int64
+		want      []string
+	}{
+		{name: "entry without grantees", timestamp: entries[0].Timestamp, want: []string{}},
+		{name: "exact timestamp", timestamp: entries[1].Timestamp, want: []string{grantee1}},
+		{name: "between entries", timestamp: entries[2].Timestamp - 1, want: []string{grantee1}},
+		{name: "after last entry", timestamp: entries[2].Timestamp + 100, want: []string{grantee1, grantee2}},
+	} {
+		grantees, err := bl.GetGranteeListAt(ctx, history, tc.timestamp)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		slices.Sort(grantees)
+		slices.Sort(tc.want)
+		if !slices.Equal(grantees, tc.want) {
+			t.Fatalf("%s: got grantees %v, want %v", tc.name, grantees, tc.want)
+		}
+	}
+}
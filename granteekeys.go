@@ -0,0 +1,280 @@
+package beelite
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/addressbook"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/feeds"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// IdentityFeedTopic is the topic, before hashing, of the identity feed of an
+// ethereum address. The public key of the address is recovered from the
+// signature of an update of the feed.
+const IdentityFeedTopic = "beelite-identity"
+
+// granteeKeyPrefix is the state store key prefix of the public keys recovered
+// for ethereum addresses.
+const granteeKeyPrefix = "beelite_grantee_key_"
+
+// ErrGranteeKeyNotFound is returned for a grantee given as an address whose
+// public key cannot be recovered, as the address neither published its
+// identity feed nor a message signed by it was added.
+var ErrGranteeKeyNotFound = errors.New("public key of grantee cannot be recovered")
+
+// identityFeedTopic returns the hashed topic of the identity feed.
+func identityFeedTopic() ([]byte, error) {
+	return crypto.LegacyKeccak256([]byte(IdentityFeedTopic))
+}
+
+// PublishIdentity publishes the identity feed of the node, so that others can
+// add it as a grantee by its ethereum address or ENS name. The public key
+// never changes, the existing update is returned if the feed is published.
+func (bl *Beelite) PublishIdentity(ctx context.Context, batchHex string) (swarm.Address, error) {
+	topic, err := identityFeedTopic()
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	feed := feeds.New(topic, bl.overlayEthAddress)
+	lookup, err := bl.feedFactory.NewLookup(feeds.Sequence, feed)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("identity feed lookup: %w", err)
+	}
+	ch, _, next, err := lookup.At(ctx, time.Now().Unix(), 0)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return swarm.ZeroAddress, fmt.Errorf("identity feed lookup: %w", err)
+	}
+	if ch != nil {
+		return ch.Address(), nil
+	}
+	if next == nil {
+		return swarm.ZeroAddress, errors.New("identity feed lookup: no next index")
+	}
+
+	batch, err := hex.DecodeString(batchHex)
+	if err != nil {
+		return swarm.ZeroAddress, errInvalidPostageBatch
+	}
+	putter, err := bl.newStamperPutter(ctx, putterOptions{
+		BatchID: batch,
+	})
+	if err != nil {
+		bl.logger.Error(err, "get putter failed")
+		return swarm.ZeroAddress, err
+	}
+
+	address, err := feed.Update(next).Address()
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	feedPutter, err := feeds.NewPutter(putter, bl.signer, topic)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	err = feedPutter.Put(ctx, next, crypto.EncodeSecp256k1PublicKey(bl.publicKey))
+	if err != nil {
+		bl.logger.Error(err, "identity feed update failed")
+		return swarm.ZeroAddress, errors.Join(err, putter.Cleanup())
+	}
+	err = putter.Done(address)
+	if err != nil {
+		bl.logger.Error(err, "done split failed")
+		return swarm.ZeroAddress, errors.Join(fmt.Errorf("done split failed: %w", err), putter.Cleanup())
+	}
+	return address, nil
+}
+
+// AddGranteeSignature recovers the public key from a message signed by an
+// ethereum account with the prefix of signed messages, like personal_sign of
+// wallets, so that the account can be added as a grantee by its address. It
+// returns the address of the account.
+func (bl *Beelite) AddGranteeSignature(message, signature []byte) (common.Address, error) {
+	key, err := crypto.Recover(signature, message)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("recover public key: %w", err)
+	}
+	owner, err := crypto.NewEthereumAddress(*key)
+	if err != nil {
+		return common.Address{}, err
+	}
+	address := common.BytesToAddress(owner)
+	if err := bl.putGranteeKey(address, key); err != nil {
+		return common.Address{}, err
+	}
+	return address, nil
+}
+
+func (bl *Beelite) putGranteeKey(address common.Address, key *ecdsa.PublicKey) error {
+	err := bl.stateStore.Put(granteeKeyPrefix+hex.EncodeToString(address.Bytes()), crypto.EncodeSecp256k1PublicKey(key))
+	if err != nil {
+		return fmt.Errorf("store grantee key: %w", err)
+	}
+	return nil
+}
+
+// granteeKeys returns the public keys of the grantees, given as hex encoded
+// public keys, ethereum addresses, overlay addresses or ENS names.
+func (bl *Beelite) granteeKeys(ctx context.Context, list []string) ([]*ecdsa.PublicKey, error) {
+	keys := make([]*ecdsa.PublicKey, 0, len(list))
+	for _, g := range list {
+		key, err := bl.granteeKey(ctx, g)
+		if err != nil {
+			return []*ecdsa.PublicKey{}, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (bl *Beelite) granteeKey(ctx context.Context, grantee string) (*ecdsa.PublicKey, error) {
+	if strings.Contains(grantee, ".") {
+		if bl.addressResolver == nil {
+			return nil, fmt.Errorf("resolve grantee %s: no name resolver", grantee)
+		}
+		address, err := bl.addressResolver.ResolveAddress(grantee)
+		if err != nil {
+			return nil, fmt.Errorf("resolve grantee %s: %w", grantee, err)
+		}
+		return bl.ethereumAddressKey(ctx, grantee, address)
+	}
+
+	h, err := hex.DecodeString(strings.TrimPrefix(grantee, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode grantee: %w", err)
+	}
+	switch len(h) {
+	case common.AddressLength:
+		return bl.ethereumAddressKey(ctx, grantee, common.BytesToAddress(h))
+	case swarm.HashSize:
+		return bl.overlayKey(grantee, swarm.NewAddress(h))
+	}
+	k, err := btcec.ParsePubKey(h)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse grantee public key: %w", err)
+	}
+	return k.ToECDSA(), nil
+}
+
+// ethereumAddressKey returns the public key of the ethereum address, recovered
+// from a message added with AddGranteeSignature or from its identity feed.
+func (bl *Beelite) ethereumAddressKey(ctx context.Context, grantee string, address common.Address) (*ecdsa.PublicKey, error) {
+	if address == bl.overlayEthAddress {
+		return bl.publicKey, nil
+	}
+
+	var b []byte
+	err := bl.stateStore.Get(granteeKeyPrefix+hex.EncodeToString(address.Bytes()), &b)
+	if err == nil {
+		k, err := btcec.ParsePubKey(b)
+		if err != nil {
+			return nil, fmt.Errorf("stored grantee key of %s: %w", grantee, err)
+		}
+		return k.ToECDSA(), nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("get grantee key: %w", err)
+	}
+
+	key, err := bl.identityFeedKey(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("grantee %s: %w", grantee, err)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w: %s has no identity feed and no signed message was added", ErrGranteeKeyNotFound, grantee)
+	}
+	if err := bl.putGranteeKey(address, key); err != nil {
+		bl.logger.Debug("grantee key not stored", "address", address, "error", err)
+	}
+	return key, nil
+}
+
+// identityFeedKey returns the public key recovered from the signature of the
+// latest update of the identity feed of the address, nil if the feed has no
+// updates.
+func (bl *Beelite) identityFeedKey(ctx context.Context, address common.Address) (*ecdsa.PublicKey, error) {
+	topic, err := identityFeedTopic()
+	if err != nil {
+		return nil, err
+	}
+	lookup, err := bl.feedFactory.NewLookup(feeds.Sequence, feeds.New(topic, address))
+	if err != nil {
+		return nil, fmt.Errorf("identity feed lookup: %w", err)
+	}
+	ch, _, _, err := lookup.At(ctx, time.Now().Unix(), 0)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("identity feed lookup: %w", err)
+	}
+	if ch == nil {
+		return nil, nil
+	}
+
+	s, err := soc.FromChunk(ch)
+	if err != nil {
+		return nil, fmt.Errorf("identity feed update: %w", err)
+	}
+	// the owner signs the hash of the id and the address of the wrapped chunk
+	h := swarm.NewHasher()
+	if _, err := h.Write(s.ID()); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(s.WrappedChunk().Address().Bytes()); err != nil {
+		return nil, err
+	}
+	key, err := crypto.Recover(s.Signature(), h.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("identity feed update: recover public key: %w", err)
+	}
+	owner, err := crypto.NewEthereumAddress(*key)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(owner, address.Bytes()) {
+		return nil, fmt.Errorf("identity feed update: signed by %x instead of %s", owner, address)
+	}
+	return key, nil
+}
+
+// overlayKey returns the public key of the overlay address, recovered from the
+// signature of the address of the peer in the address book.
+func (bl *Beelite) overlayKey(grantee string, overlay swarm.Address) (*ecdsa.PublicKey, error) {
+	if overlay.Equal(bl.overlay) {
+		return bl.publicKey, nil
+	}
+
+	bzzAddress, err := bl.addressBook.Get(overlay)
+	if errors.Is(err, addressbook.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s is not a known peer", ErrGranteeKeyNotFound, grantee)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("grantee %s: %w", grantee, err)
+	}
+
+	// the peer signs its underlay, overlay and network id in the handshake
+	networkID := make([]byte, 8)
+	binary.BigEndian.PutUint64(networkID, bl.networkID)
+	signData := append([]byte("bee-handshake-"), bzzAddress.Underlay.Bytes()...)
+	signData = append(signData, overlay.Bytes()...)
+	signData = append(signData, networkID...)
+	key, err := crypto.Recover(bzzAddress.Signature, signData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrGranteeKeyNotFound, grantee, err)
+	}
+	recovered, err := crypto.NewOverlayAddress(*key, bl.networkID, bzzAddress.Nonce)
+	if err != nil || !recovered.Equal(overlay) {
+		return nil, fmt.Errorf("%w: %s: address book signature does not match the overlay", ErrGranteeKeyNotFound, grantee)
+	}
+	return key, nil
+}
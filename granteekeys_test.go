@@ -0,0 +1,176 @@
+package beelite
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/addressbook"
+	"github.com/ethersphere/bee/v2/pkg/bzz"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/feeds/factory"
+	"github.com/ethersphere/bee/v2/pkg/feeds/sequence"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/storage/inmemchunkstore"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const testNetworkID = 10
+
+func testEthereumAddress(t *testing.T, key *ecdsa.PrivateKey) common.Address {
+	t.Helper()
+
+	address, err := crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return common.BytesToAddress(address)
+}
+
+func TestGranteeKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	chunkStore := inmemchunkstore.New()
+	stateStore := mock.NewStateStore()
+	book := addressbook.New(stateStore)
+
+	nodeKey, _ := testSignerKey(t)
+	nodeOverlay, err := crypto.NewOverlayAddress(nodeKey.PublicKey, testNetworkID, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bl := &Beelite{
+		overlayEthAddress: testEthereumAddress(t, nodeKey),
+		publicKey:         &nodeKey.PublicKey,
+		overlay:           nodeOverlay,
+		feedFactory:       factory.New(chunkStore),
+		stateStore:        stateStore,
+		addressBook:       book,
+		networkID:         testNetworkID,
+		logger:            log.Noop,
+	}
+
+	// an account which signed a message
+	signedKey, _ := testSignerKey(t)
+	message := []byte("grant access")
+	signature, err := crypto.NewDefaultSigner(signedKey).Sign(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedAddress, err := bl.AddGranteeSignature(message, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signedAddress != testEthereumAddress(t, signedKey) {
+		t.Fatalf("got address %s of signed message, want %s", signedAddress, testEthereumAddress(t, signedKey))
+	}
+
+	// an account which published its identity feed
+	feedKey, _ := testSignerKey(t)
+	topic, err := identityFeedTopic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	updater, err := sequence.NewUpdater(chunkStore, crypto.NewDefaultSigner(feedKey), topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.Update(ctx, time.Now().Unix(), crypto.EncodeSecp256k1PublicKey(&feedKey.PublicKey)); err != nil {
+		t.Fatal(err)
+	}
+
+	// a peer in the address book
+	peerKey, _ := testSignerKey(t)
+	nonce := make([]byte, 32)
+	peerOverlay, err := crypto.NewOverlayAddress(peerKey.PublicKey, testNetworkID, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	underlay, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/1634/p2p/16Uiu2HAmTm17toLDaPYzRyjKn27iCB76yjKnJ5DjQXneFmifFvaX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bzzAddress, err := bzz.NewAddress(crypto.NewDefaultSigner(peerKey), underlay, peerOverlay, testNetworkID, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := book.Put(peerOverlay, *bzzAddress); err != nil {
+		t.Fatal(err)
+	}
+
+	unknownKey, _ := testSignerKey(t)
+	unknownOverlay, err := crypto.NewOverlayAddress(unknownKey.PublicKey, testNetworkID, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		grantee string
+		want    *ecdsa.PublicKey
+		wantErr error
+	}{
+		{
+			name:    "public key",
+			grantee: hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&unknownKey.PublicKey)),
+			want:    &unknownKey.PublicKey,
+		},
+		{
+			name:    "own ethereum address",
+			grantee: bl.overlayEthAddress.Hex(),
+			want:    &nodeKey.PublicKey,
+		},
+		{
+			name:    "ethereum address of signed message",
+			grantee: signedAddress.Hex(),
+			want:    &signedKey.PublicKey,
+		},
+		{
+			name:    "ethereum address of identity feed",
+			grantee: testEthereumAddress(t, feedKey).Hex(),
+			want:    &feedKey.PublicKey,
+		},
+		{
+			name:    "unknown ethereum address",
+			grantee: testEthereumAddress(t, unknownKey).Hex(),
+			wantErr: ErrGranteeKeyNotFound,
+		},
+		{
+			name:    "own overlay",
+			grantee: nodeOverlay.String(),
+			want:    &nodeKey.PublicKey,
+		},
+		{
+			name:    "overlay in address book",
+			grantee: peerOverlay.String(),
+			want:    &peerKey.PublicKey,
+		},
+		{
+			name:    "unknown overlay",
+			grantee: unknownOverlay.String(),
+			wantErr: ErrGranteeKeyNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := bl.granteeKey(ctx, tc.grantee)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if tc.want != nil && (key == nil || !key.Equal(tc.want)) {
+				t.Fatalf("got key %v, want %v", key, tc.want)
+			}
+		})
+	}
+
+	for _, grantee := range []string{"not hex", "0x1234", "swarm.eth"} {
+		if _, err := bl.granteeKey(ctx, grantee); err == nil {
+			t.Errorf("%s: got no error", grantee)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package beelite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	"github.com/ethersphere/bee/v2/pkg/resolver/multiresolver"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/util/nbhdutil"
+	goens "github.com/wealdtech/go-ens/v3"
+)
+
+const (
+	// NeighborhoodSuggesterDisabled as LiteOptions.NeighborhoodSuggester turns
+	// off the neighborhood suggestion.
+	NeighborhoodSuggesterDisabled = "none"
+
+	mainnetNeighborhoodSuggester = "https://api.swarmscan.io/v1/network/neighborhoods/suggestion"
+	defaultENSContractAddress    = "00000000000C2E074eC69A0dFb2997BA6C7d2e1e"
+	swarmContentHashPrefix       = "bzz://"
+)
+
+var errNameNotRegistered = errors.New("name is not registered")
+
+// NeighborhoodSuggesterFunc returns the neighborhood, as a bit string, for
+// which a new node mines its overlay address.
+type NeighborhoodSuggesterFunc func(ctx context.Context) (string, error)
+
+// httpClient returns the client of the outbound HTTP requests of the node.
+func (lo *LiteOptions) httpClient() (*http.Client, error) {
+	if lo.HTTPClient != nil {
+		return lo.HTTPClient, nil
+	}
+	if lo.HTTPProxy == "" {
+		return &http.Client{}, nil
+	}
+	proxy, err := url.Parse(lo.HTTPProxy)
+	if err != nil {
+		return nil, fmt.Errorf("http proxy: %w", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxy)
+	return &http.Client{Transport: transport}, nil
+}
+
+// neighborhoodSuggester returns the suggester of the neighborhood of a new
+// node, nil if it is disabled.
+func (lo *LiteOptions) neighborhoodSuggester(client *http.Client) NeighborhoodSuggesterFunc {
+	if lo.NeighborhoodSuggesterFunc != nil {
+		return lo.NeighborhoodSuggesterFunc
+	}
+
+	suggester := lo.NeighborhoodSuggester
+	if suggester == "" && lo.NetworkID == chaincfg.Mainnet.NetworkID {
+		suggester = mainnetNeighborhoodSuggester
+	}
+	if suggester == "" || suggester == NeighborhoodSuggesterDisabled {
+		return nil
+	}
+	return func(context.Context) (string, error) {
+		return nbhdutil.FetchNeighborhood(client, suggester)
+	}
+}
+
+// endpointRecorder records the endpoints of the requests made with it, so
+// that the node can report which external services it contacted.
+type endpointRecorder struct {
+	next http.RoundTripper
+
+	mu        sync.Mutex
+	endpoints map[string]struct{}
+}
+
+// recordEndpoints returns a copy of client which records the endpoints of
+// its requests.
+func recordEndpoints(client *http.Client) (*http.Client, *endpointRecorder) {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &endpointRecorder{next: next, endpoints: make(map[string]struct{})}
+	c := *client
+	c.Transport = r
+	return &c, r
+}
+
+func (r *endpointRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.add(req.URL.Scheme + "://" + req.URL.Host)
+	return r.next.RoundTrip(req)
+}
+
+func (r *endpointRecorder) add(endpoint string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints[endpoint] = struct{}{}
+}
+
+// list returns the recorded endpoints in order.
+func (r *endpointRecorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	endpoints := make([]string, 0, len(r.endpoints))
+	for e := range r.endpoints {
+		endpoints = append(endpoints, e)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
+
+// dialRPC connects to an ethereum endpoint, over HTTP with the given client.
+func dialRPC(ctx context.Context, endpoint string, client *http.Client) (*rpc.Client, error) {
+	return rpc.DialOptions(ctx, endpoint, rpc.WithHTTPClient(client))
+}
+
+// newMultiResolver returns the name resolver of the node, with the ENS
+// clients connecting through client, and the resolver of names to ethereum
+// addresses using the same clients.
+func newMultiResolver(ctx context.Context, cfgs []multiresolver.ConnectionConfig, logger log.Logger, client *http.Client) (*multiresolver.MultiResolver, *ensAddressResolver) {
+	mr := multiresolver.NewMultiResolver(
+		multiresolver.WithLogger(logger),
+		multiresolver.WithDefaultCIDResolver(),
+	)
+	ar := &ensAddressResolver{chains: make(map[string][]*ensResolver)}
+	for _, c := range cfgs {
+		r, err := newENSResolver(ctx, c.Endpoint, c.Address, client)
+		if err != nil {
+			logger.Error(err, "resolver on endpoint failed", "tld", c.TLD, "endpoint", c.Endpoint)
+			continue
+		}
+		logger.Info("connected", "tld", c.TLD, "endpoint", c.Endpoint)
+		mr.PushResolver(c.TLD, r)
+		ar.chains[c.TLD] = append(ar.chains[c.TLD], r)
+	}
+	return mr, ar
+}
+
+// ensAddressResolver resolves names to ethereum addresses with the chain of
+// ENS clients of the top level domain of the name, like the multiresolver.
+// The clients are closed by the multiresolver.
+type ensAddressResolver struct {
+	chains map[string][]*ensResolver
+}
+
+func (ar *ensAddressResolver) ResolveAddress(name string) (common.Address, error) {
+	chain := ar.chains[path.Ext(strings.ToLower(name))]
+	if len(chain) == 0 {
+		chain = ar.chains[""]
+	}
+	if len(chain) == 0 {
+		return common.Address{}, fmt.Errorf("%w: no resolver for %s", resolver.ErrServiceNotAvailable, name)
+	}
+
+	var errs error
+	for _, r := range chain {
+		addr, err := r.ResolveAddress(name)
+		if err == nil {
+			return addr, nil
+		}
+		errs = errors.Join(errs, err)
+	}
+	return common.Address{}, errs
+}
+
+// ensResolver resolves names with ENS like the bee ENS client.
+type ensResolver struct {
+	ethCl    *ethclient.Client
+	registry *goens.Registry
+}
+
+func newENSResolver(ctx context.Context, endpoint, contractAddr string, client *http.Client) (*ensResolver, error) {
+	if contractAddr == "" {
+		contractAddr = defaultENSContractAddress
+	}
+	rpcClient, err := dialRPC(ctx, endpoint, client)
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+	ethCl := ethclient.NewClient(rpcClient)
+
+	registry, err := goens.NewRegistryAt(ethCl, common.HexToAddress(contractAddr))
+	if err != nil {
+		ethCl.Close()
+		return nil, fmt.Errorf("new registry: %w", err)
+	}
+	// Ensure that the ENS registry is deployed to the given contract address.
+	if _, err := registry.Owner(""); err != nil {
+		ethCl.Close()
+		return nil, fmt.Errorf("owner: %w", err)
+	}
+	return &ensResolver{ethCl: ethCl, registry: registry}, nil
+}
+
+func (r *ensResolver) Resolve(name string) (swarm.Address, error) {
+	owner, err := r.registry.Owner(name)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("owner: %w: %w", err, resolver.ErrNotFound)
+	}
+	if bytes.Equal(owner.Bytes(), goens.UnknownAddress.Bytes()) {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %w", errNameNotRegistered, resolver.ErrNotFound)
+	}
+
+	ensR, err := r.registry.Resolver(name)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("resolver: %w: %w", err, resolver.ErrServiceNotAvailable)
+	}
+	ch, err := ensR.Contenthash()
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("contenthash: %w: %w", err, resolver.ErrInvalidContentHash)
+	}
+	hash, err := goens.ContenthashToString(ch)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("contenthash to string: %w: %w", err, resolver.ErrInvalidContentHash)
+	}
+
+	if !strings.HasPrefix(hash, swarmContentHashPrefix) {
+		return swarm.ZeroAddress, fmt.Errorf("check content hash prefix %s: %w", hash, resolver.ErrInvalidContentHash)
+	}
+	addr, err := swarm.ParseHexAddress(strings.TrimPrefix(hash, swarmContentHashPrefix))
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("parse response hash %s: %w", hash, resolver.ErrInvalidContentHash)
+	}
+	return addr, nil
+}
+
+// ResolveAddress returns the ethereum address the name is set to.
+func (r *ensResolver) ResolveAddress(name string) (common.Address, error) {
+	owner, err := r.registry.Owner(name)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("owner: %w: %w", err, resolver.ErrNotFound)
+	}
+	if bytes.Equal(owner.Bytes(), goens.UnknownAddress.Bytes()) {
+		return common.Address{}, fmt.Errorf("%w: %w", errNameNotRegistered, resolver.ErrNotFound)
+	}
+
+	ensR, err := r.registry.Resolver(name)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("resolver: %w: %w", err, resolver.ErrServiceNotAvailable)
+	}
+	addr, err := ensR.Address()
+	if err != nil {
+		return common.Address{}, fmt.Errorf("address: %w: %w", err, resolver.ErrNotFound)
+	}
+	if bytes.Equal(addr.Bytes(), goens.UnknownAddress.Bytes()) {
+		return common.Address{}, fmt.Errorf("address of %s not set: %w", name, resolver.ErrNotFound)
+	}
+	return addr, nil
+}
+
+func (r *ensResolver) Close() error {
+	r.ethCl.Close()
+	return nil
+}
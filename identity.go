@@ -0,0 +1,351 @@
+package beelite
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/statestore/storeadapter"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/storage/leveldbstore"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	identityBundleVersion = 1
+	mnemonicEntropyBits   = 128
+	hdHardened            = 0x80000000
+)
+
+// ethereumDerivationPath is m/44'/60'/0'/0/0, the first account of ethereum
+// wallets, so the swarm key matches the wallet of the mnemonic.
+var ethereumDerivationPath = []uint32{44 + hdHardened, 60 + hdHardened, 0 + hdHardened, 0, 0}
+
+var (
+	ErrIdentityExists      = errors.New("node identity already exists in the data directory")
+	errDataDirRequired     = errors.New("data directory not provided")
+	errInvalidMnemonic     = errors.New("invalid mnemonic")
+	errInvalidDerivedKey   = errors.New("invalid derived key")
+	errUnsupportedIdentity = errors.New("unsupported identity bundle version")
+)
+
+// identityBundle holds the encrypted keys of a node in the V3 JSON format of
+// the keystore together with the nonce of its overlay address.
+type identityBundle struct {
+	Version int                        `json:"version"`
+	Keys    map[string]json.RawMessage `json:"keys"`
+	Nonce   string                     `json:"nonce"`
+}
+
+// ExportIdentity returns the swarm, libp2p and pss keys of the node in
+// dataDir, encrypted with password, and the overlay nonce as a JSON bundle
+// which ImportIdentity accepts. The node must not be running.
+func ExportIdentity(dataDir, password string) ([]byte, error) {
+	if dataDir == "" {
+		return nil, errDataDirRequired
+	}
+
+	bundle := identityBundle{
+		Version: identityBundleVersion,
+		Keys:    make(map[string]json.RawMessage, len(nodeKeys)),
+	}
+	for _, k := range nodeKeys {
+		// Decrypt to check the password, the bundle holds the file as it is.
+		if _, err := readKey(dataDir, k.name, password, k.edg); err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(keyFilename(dataDir, k.name))
+		if err != nil {
+			return nil, fmt.Errorf("read %s key: %w", k.name, err)
+		}
+		bundle.Keys[k.name] = data
+	}
+
+	stateStore, err := openStateStore(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("init state store: %w", err)
+	}
+	defer stateStore.Close()
+
+	nonce, _, err := overlayNonceExists(stateStore)
+	if err != nil {
+		return nil, fmt.Errorf("overlay nonce: %w", err)
+	}
+	bundle.Nonce = hex.EncodeToString(nonce)
+
+	return json.Marshal(bundle)
+}
+
+// ImportIdentity writes the keys and the overlay nonce of a bundle created by
+// ExportIdentity to dataDir, so that the node started there has the same
+// overlay address and ethereum address. It must be called before Start and
+// returns ErrIdentityExists if dataDir already has a swarm key.
+func ImportIdentity(dataDir string, bundle []byte, password string) error {
+	if dataDir == "" {
+		return errDataDirRequired
+	}
+	if err := checkNoIdentity(dataDir); err != nil {
+		return err
+	}
+
+	var b identityBundle
+	if err := json.Unmarshal(bundle, &b); err != nil {
+		return fmt.Errorf("unmarshal identity bundle: %w", err)
+	}
+	if b.Version != identityBundleVersion {
+		return errUnsupportedIdentity
+	}
+	nonce, err := hex.DecodeString(b.Nonce)
+	if err != nil || len(nonce) != 32 {
+		return fmt.Errorf("invalid overlay nonce %q", b.Nonce)
+	}
+
+	// The keys are written to a staging directory in dataDir and checked to
+	// decrypt before any of the keys or the state of dataDir are replaced.
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return err
+	}
+	stagingDir, err := os.MkdirTemp(dataDir, ".identity-import")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stagingDir)
+	for _, k := range nodeKeys {
+		data, ok := b.Keys[k.name]
+		if !ok {
+			return fmt.Errorf("identity bundle: %s key missing", k.name)
+		}
+		if err := writeFileAtomic(keyFilename(stagingDir, k.name), data); err != nil {
+			return err
+		}
+		if _, err := readKey(stagingDir, k.name, password, k.edg); err != nil {
+			return err
+		}
+	}
+
+	stateStore, err := openStateStore(dataDir)
+	if err != nil {
+		return fmt.Errorf("init state store: %w", err)
+	}
+	defer stateStore.Close()
+
+	restore, err := replaceKeys(stagingDir, dataDir)
+	if err != nil {
+		return err
+	}
+
+	// The stored overlay belongs to the replaced identity, it is set again on start.
+	err = stateStore.Delete(noncedOverlayKey)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		err = fmt.Errorf("delete overlay: %w", err)
+	} else if err = stateStore.Put(overlayNonce, nonce); err != nil {
+		err = fmt.Errorf("store overlay nonce: %w", err)
+	}
+	if err != nil {
+		if rollbackErr := restore(); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback: %w", rollbackErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// replaceKeys moves the node keys of stagingDir into dataDir, the swarm key
+// last as it marks an existing identity. On failure the keys of dataDir are
+// restored, the returned function restores them after a later failure.
+func replaceKeys(stagingDir, dataDir string) (restore func() error, err error) {
+	type replaced struct {
+		filename string
+		old      []byte
+	}
+	var done []replaced
+	restore = func() error {
+		var err error
+		for _, r := range done {
+			if r.old != nil {
+				err = errors.Join(err, writeFileAtomic(r.filename, r.old))
+			} else {
+				// the key did not exist before the import
+				err = errors.Join(err, os.Remove(r.filename))
+			}
+		}
+		return err
+	}
+
+	for i := len(nodeKeys) - 1; i >= 0; i-- {
+		k := nodeKeys[i]
+		filename := keyFilename(dataDir, k.name)
+		old, err := os.ReadFile(filename)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, errors.Join(fmt.Errorf("read %s key: %w", k.name, err), restore())
+		}
+		if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+			return nil, errors.Join(err, restore())
+		}
+		if err := os.Rename(keyFilename(stagingDir, k.name), filename); err != nil {
+			return nil, errors.Join(fmt.Errorf("write %s key: %w", k.name, err), restore())
+		}
+		done = append(done, replaced{filename: filename, old: old})
+	}
+	return restore, nil
+}
+
+// NewMnemonic returns a new random BIP-39 mnemonic of 12 words.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// ImportMnemonic derives the swarm key from a BIP-39 mnemonic, as the first
+// account of an ethereum wallet, and stores it in dataDir encrypted with
+// password. The libp2p and pss keys are created on Start. It returns
+// ErrIdentityExists if dataDir already has a swarm key.
+func ImportMnemonic(dataDir, mnemonic, password string) error {
+	if dataDir == "" {
+		return errDataDirRequired
+	}
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return errInvalidMnemonic
+	}
+	if err := checkNoIdentity(dataDir); err != nil {
+		return err
+	}
+
+	key, err := deriveKey(bip39.NewSeed(mnemonic, ""), ethereumDerivationPath)
+	if err != nil {
+		return err
+	}
+	data, err := encryptKey(key, password, crypto.EDGSecp256_K1)
+	if err != nil {
+		return fmt.Errorf("encrypt swarm key: %w", err)
+	}
+	return writeFileAtomic(keyFilename(dataDir, swarmKeyName), data)
+}
+
+// openStateStore opens the state store of dataDir while the node is not
+// running. Unlike node.InitStateStore, closing it releases the database.
+func openStateStore(dataDir string) (storage.StateStorerManager, error) {
+	ldb, err := leveldbstore.New(filepath.Join(dataDir, "statestore"), nil)
+	if err != nil {
+		return nil, err
+	}
+	stateStore, err := storeadapter.NewStateStorerAdapter(ldb)
+	if err != nil {
+		return nil, errors.Join(err, ldb.Close())
+	}
+	return stateStore, nil
+}
+
+func checkNoIdentity(dataDir string) error {
+	_, err := os.Stat(keyFilename(dataDir, swarmKeyName))
+	switch {
+	case err == nil:
+		return ErrIdentityExists
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	default:
+		return err
+	}
+}
+
+// deriveKey derives the BIP-32 private key of the path from the seed.
+func deriveKey(seed []byte, path []uint32) (*ecdsa.PrivateKey, error) {
+	sum := hmacSHA512([]byte("Bitcoin seed"), seed)
+	var key btcec.ModNScalar
+	if overflow := key.SetByteSlice(sum[:32]); overflow || key.IsZero() {
+		return nil, errInvalidDerivedKey
+	}
+	chainCode := sum[32:]
+
+	for _, i := range path {
+		var data []byte
+		if i >= hdHardened {
+			k := key.Bytes()
+			data = append([]byte{0}, k[:]...)
+		} else {
+			k := key.Bytes()
+			priv, _ := btcec.PrivKeyFromBytes(k[:])
+			data = priv.PubKey().SerializeCompressed()
+		}
+		data = binary.BigEndian.AppendUint32(data, i)
+
+		sum := hmacSHA512(chainCode, data)
+		var tweak btcec.ModNScalar
+		if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+			return nil, errInvalidDerivedKey
+		}
+		key.Add(&tweak)
+		if key.IsZero() {
+			return nil, errInvalidDerivedKey
+		}
+		chainCode = sum[32:]
+	}
+
+	k := key.Bytes()
+	priv, _ := btcec.PrivKeyFromBytes(k[:])
+	return priv.ToECDSA(), nil
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// ChangePassword re-encrypts the swarm, libp2p and pss keys in dataDir with
+// newPassword. Either all keys are changed or, on failure, the keys which
+// were already written are restored. The node must not be running.
+func ChangePassword(dataDir, oldPassword, newPassword string) error {
+	if dataDir == "" {
+		return errDataDirRequired
+	}
+
+	type change struct {
+		filename string
+		old      []byte
+		new      []byte
+	}
+	changes := make([]change, 0, len(nodeKeys))
+	for _, k := range nodeKeys {
+		key, err := readKey(dataDir, k.name, oldPassword, k.edg)
+		if err != nil {
+			return err
+		}
+		filename := keyFilename(dataDir, k.name)
+		old, err := os.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("read %s key: %w", k.name, err)
+		}
+		data, err := encryptKey(key, newPassword, k.edg)
+		if err != nil {
+			return fmt.Errorf("encrypt %s key: %w", k.name, err)
+		}
+		changes = append(changes, change{filename: filename, old: old, new: data})
+	}
+
+	for i, c := range changes {
+		if err := writeFileAtomic(c.filename, c.new); err != nil {
+			var rollbackErr error
+			for _, w := range changes[:i] {
+				rollbackErr = errors.Join(rollbackErr, writeFileAtomic(w.filename, w.old))
+			}
+			if rollbackErr != nil {
+				return errors.Join(fmt.Errorf("write %s: %w", c.filename, err), fmt.Errorf("rollback: %w", rollbackErr))
+			}
+			return fmt.Errorf("write %s: %w", c.filename, err)
+		}
+	}
+	return nil
+}
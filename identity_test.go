@@ -0,0 +1,237 @@
+package beelite
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// writeTestIdentity writes the keys of a node encrypted with password and
+// its overlay nonce to dataDir.
+func writeTestIdentity(t *testing.T, dataDir, password string, nonce []byte) map[string]*ecdsa.PrivateKey {
+	t.Helper()
+
+	keys := make(map[string]*ecdsa.PrivateKey, len(nodeKeys))
+	for _, k := range nodeKeys {
+		key, err := crypto.GenerateSecp256k1Key()
+		if k.name == libp2pKeyName {
+			key, err = crypto.GenerateSecp256r1Key()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := encryptKey(key, password, k.edg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writeFileAtomic(keyFilename(dataDir, k.name), data); err != nil {
+			t.Fatal(err)
+		}
+		keys[k.name] = key
+	}
+
+	stateStore, err := openStateStore(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stateStore.Close()
+	overlay, err := crypto.NewOverlayAddress(keys[swarmKeyName].PublicKey, testNetworkID, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := setOverlay(stateStore, overlay, nonce); err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func readTestNonce(t *testing.T, dataDir string) ([]byte, swarm.Address) {
+	t.Helper()
+
+	stateStore, err := openStateStore(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stateStore.Close()
+	nonce, _, err := overlayNonceExists(stateStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var overlay swarm.Address
+	_ = stateStore.Get(noncedOverlayKey, &overlay)
+	return nonce, overlay
+}
+
+func TestExportImportIdentity(t *testing.T) {
+	t.Parallel()
+
+	const password = "secret"
+	nonce := bytes.Repeat([]byte{7}, 32)
+	dataDir := t.TempDir()
+	keys := writeTestIdentity(t, dataDir, password, nonce)
+
+	if _, err := ExportIdentity(dataDir, "wrong"); err == nil {
+		t.Fatal("exported identity with wrong password")
+	}
+	bundle, err := ExportIdentity(dataDir, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ImportIdentity(dataDir, bundle, password); !errors.Is(err, ErrIdentityExists) {
+		t.Fatalf("got error %v, want %v", err, ErrIdentityExists)
+	}
+
+	newDir := t.TempDir()
+	if err := ImportIdentity(newDir, bundle, password); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range nodeKeys {
+		key, err := readKey(newDir, k.name, password, k.edg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !key.Equal(keys[k.name]) {
+			t.Fatalf("imported %s key does not match the exported one", k.name)
+		}
+	}
+	gotNonce, overlay := readTestNonce(t, newDir)
+	if !bytes.Equal(gotNonce, nonce) {
+		t.Fatalf("got nonce %x, want %x", gotNonce, nonce)
+	}
+	if !overlay.IsZero() {
+		t.Fatalf("got stored overlay %s, want none", overlay)
+	}
+}
+
+func TestImportIdentityKeepsKeysOnFailure(t *testing.T) {
+	t.Parallel()
+
+	const password = "secret"
+	srcDir := t.TempDir()
+	writeTestIdentity(t, srcDir, password, bytes.Repeat([]byte{7}, 32))
+	bundle, err := ExportIdentity(srcDir, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		password string
+		// unwritable is a key replaced by a directory
+		unwritable string
+	}{
+		{name: "wrong password", password: "wrong"},
+		{name: "key not writable", password: password, unwritable: libp2pKeyName},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// a data directory with the keys of another node except the swarm key
+			dataDir := t.TempDir()
+			oldNonce := bytes.Repeat([]byte{1}, 32)
+			writeTestIdentity(t, dataDir, password, oldNonce)
+			if err := os.Remove(keyFilename(dataDir, swarmKeyName)); err != nil {
+				t.Fatal(err)
+			}
+			if tc.unwritable != "" {
+				filename := keyFilename(dataDir, tc.unwritable)
+				if err := os.Remove(filename); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.MkdirAll(filepath.Join(filename, "dir"), 0700); err != nil {
+					t.Fatal(err)
+				}
+			}
+			old := make(map[string][]byte)
+			for _, name := range []string{libp2pKeyName, pssKeyName} {
+				if name == tc.unwritable {
+					continue
+				}
+				data, err := os.ReadFile(keyFilename(dataDir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				old[name] = data
+			}
+
+			if err := ImportIdentity(dataDir, bundle, tc.password); err == nil {
+				t.Fatal("identity imported")
+			}
+			for name, data := range old {
+				got, err := os.ReadFile(keyFilename(dataDir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, data) {
+					t.Fatalf("%s key changed by the failed import", name)
+				}
+			}
+			if _, err := os.Stat(keyFilename(dataDir, swarmKeyName)); !errors.Is(err, os.ErrNotExist) {
+				t.Fatalf("swarm key written by the failed import: %v", err)
+			}
+			if nonce, _ := readTestNonce(t, dataDir); !bytes.Equal(nonce, oldNonce) {
+				t.Fatalf("nonce changed by the failed import to %x", nonce)
+			}
+		})
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	t.Parallel()
+
+	// test vector 1 of BIP-32
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	for _, tc := range []struct {
+		path []uint32
+		want string
+	}{
+		{path: nil, want: "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{path: []uint32{0 + hdHardened}, want: "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{path: []uint32{0 + hdHardened, 1}, want: "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{path: []uint32{0 + hdHardened, 1, 2 + hdHardened, 2, 1000000000}, want: "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	} {
+		key, err := deriveKey(seed, tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key.D.FillBytes(make([]byte, 32))); got != tc.want {
+			t.Errorf("path %v: got key %s, want %s", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestImportMnemonic(t *testing.T) {
+	t.Parallel()
+
+	const (
+		mnemonic = "test test test test test test test test test test test junk"
+		// the first account of ethereum wallets of the mnemonic
+		want = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+	)
+
+	dataDir := t.TempDir()
+	if err := ImportMnemonic(dataDir, "test test test", "secret"); !errors.Is(err, errInvalidMnemonic) {
+		t.Fatalf("got error %v, want %v", err, errInvalidMnemonic)
+	}
+	if err := ImportMnemonic(dataDir, mnemonic, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	key, err := readKey(dataDir, swarmKeyName, "secret", crypto.EDGSecp256_K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := testEthereumAddress(t, key).Hex(); got != want {
+		t.Fatalf("got address %s, want %s", got, want)
+	}
+	if err := ImportMnemonic(dataDir, mnemonic, "secret"); !errors.Is(err, ErrIdentityExists) {
+		t.Fatalf("got error %v, want %v", err, ErrIdentityExists)
+	}
+}
@@ -0,0 +1,130 @@
+package beelite
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	ethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/keystore"
+	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
+	"github.com/google/uuid"
+)
+
+const (
+	keyFileVersion = 3
+	keysDir        = "keys"
+	// the scrypt parameters of the bee file keystore, light enough to decrypt
+	// the keys on phones
+	keyScryptN = 1 << 15
+	keyScryptP = 1
+)
+
+// keyFile is the ethereum V3 JSON format of the bee file keystore.
+type keyFile struct {
+	Address string                 `json:"address"`
+	Crypto  ethkeystore.CryptoJSON `json:"crypto"`
+	Version int                    `json:"version"`
+	ID      string                 `json:"id"`
+}
+
+// nodeKeys are the names and encodings of the keys in the keystore of a node.
+var nodeKeys = []struct {
+	name string
+	edg  keystore.EDG
+}{
+	{swarmKeyName, crypto.EDGSecp256_K1},
+	{libp2pKeyName, crypto.EDGSecp256_R1},
+	{pssKeyName, crypto.EDGSecp256_K1},
+}
+
+func keyFilename(dataDir, name string) string {
+	return filepath.Join(dataDir, keysDir, name+".key")
+}
+
+// encryptKey encodes the key in the format of the bee file keystore.
+func encryptKey(k *ecdsa.PrivateKey, password string, edg keystore.EDG) ([]byte, error) {
+	data, err := edg.Encode(k)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ethkeystore.EncryptDataV3(data, []byte(password), keyScryptN, keyScryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	var addr []byte
+	switch k.PublicKey.Curve {
+	case btcec.S256():
+		if addr, err = crypto.NewEthereumAddress(k.PublicKey); err != nil {
+			return nil, err
+		}
+	case elliptic.P256():
+		ecdhKey, err := k.ECDH()
+		if err != nil {
+			return nil, err
+		}
+		addr = ecdhKey.PublicKey().Bytes()
+	default:
+		return nil, fmt.Errorf("unsupported curve: %v", k.PublicKey.Curve)
+	}
+
+	return json.Marshal(keyFile{
+		Address: hex.EncodeToString(addr),
+		Crypto:  c,
+		Version: keyFileVersion,
+		ID:      uuid.NewString(),
+	})
+}
+
+// readKey decrypts an existing key of the file keystore of the node.
+func readKey(dataDir, name, password string, edg keystore.EDG) (*ecdsa.PrivateKey, error) {
+	ks := filekeystore.New(filepath.Join(dataDir, keysDir))
+	exists, err := ks.Exists(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s key: %w", name, os.ErrNotExist)
+	}
+	k, _, err := ks.Key(name, password, edg)
+	if err != nil {
+		return nil, fmt.Errorf("%s key: %w", name, err)
+	}
+	return k, nil
+}
+
+// writeFileAtomic replaces the file with data through a temporary file in
+// the same directory, so that it is never left half written.
+func writeFileAtomic(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
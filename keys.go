@@ -0,0 +1,95 @@
+package beelite
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"sync"
+
+	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/keystore"
+)
+
+const (
+	swarmKeyName  = "swarm"
+	libp2pKeyName = "libp2p_v2"
+	pssKeyName    = "pss"
+)
+
+// KeyProvider supplies the keys of the node identity. Implementations can keep
+// the swarm key outside of the process, e.g. in the Android Keystore or in a
+// wallet, as long as they can sign with it and derive the access control keys.
+type KeyProvider interface {
+	// Signer signs with the swarm key, which determines the overlay address
+	// and the ethereum address of the node.
+	Signer() (crypto.Signer, error)
+	// Session derives the access control keys with the swarm key.
+	Session() (accesscontrol.Session, error)
+	// Libp2pKey returns the key of the libp2p host, it has to be a P-256 key.
+	Libp2pKey() (*ecdsa.PrivateKey, error)
+	// PSSKey returns the key used to decrypt pss messages.
+	PSSKey() (*ecdsa.PrivateKey, error)
+}
+
+// NewKeystoreKeyProvider returns a KeyProvider with the keys of a bee
+// keystore encrypted with password. Missing keys are created. It is the
+// default KeyProvider, with the file keystore under DataDir/keys.
+func NewKeystoreKeyProvider(ks keystore.Service, password string) KeyProvider {
+	return &keystoreKeyProvider{ks: ks, password: password}
+}
+
+type keystoreKeyProvider struct {
+	ks       keystore.Service
+	password string
+
+	// the swarm key is decrypted once for the signer and the session
+	mu  sync.Mutex
+	key *ecdsa.PrivateKey
+}
+
+func (p *keystoreKeyProvider) swarmKey() (*ecdsa.PrivateKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.key != nil {
+		return p.key, nil
+	}
+	key, _, err := p.ks.Key(swarmKeyName, p.password, crypto.EDGSecp256_K1)
+	if err != nil {
+		return nil, fmt.Errorf("swarm key: %w", err)
+	}
+	p.key = key
+	return key, nil
+}
+
+func (p *keystoreKeyProvider) Signer() (crypto.Signer, error) {
+	key, err := p.swarmKey()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewDefaultSigner(key), nil
+}
+
+func (p *keystoreKeyProvider) Session() (accesscontrol.Session, error) {
+	key, err := p.swarmKey()
+	if err != nil {
+		return nil, err
+	}
+	return accesscontrol.NewDefaultSession(key), nil
+}
+
+func (p *keystoreKeyProvider) Libp2pKey() (*ecdsa.PrivateKey, error) {
+	key, _, err := p.ks.Key(libp2pKeyName, p.password, crypto.EDGSecp256_R1)
+	if err != nil {
+		return nil, fmt.Errorf("libp2p v2 key: %w", err)
+	}
+	return key, nil
+}
+
+func (p *keystoreKeyProvider) PSSKey() (*ecdsa.PrivateKey, error) {
+	key, _, err := p.ks.Key(pssKeyName, p.password, crypto.EDGSecp256_K1)
+	if err != nil {
+		return nil, fmt.Errorf("pss key: %w", err)
+	}
+	return key, nil
+}
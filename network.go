@@ -0,0 +1,213 @@
+package beelite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/node"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+	"github.com/ethersphere/bee/v2/pkg/transaction/wrapped"
+)
+
+// NetworkProfile describes a swarm network which bee does not know about,
+// e.g. a private swarm running its own deployment of the storage incentives
+// contracts.
+type NetworkProfile struct {
+	NetworkID              uint64        `yaml:"network-id"`
+	ChainID                int64         `yaml:"chain-id"`
+	BlockTime              time.Duration `yaml:"block-time"`
+	Bootnodes              []string      `yaml:"bootnodes"`
+	PostageStampAddress    string        `yaml:"postage-stamp-address"`
+	PostageStampStartBlock uint64        `yaml:"postage-stamp-start-block"`
+	StakingAddress         string        `yaml:"staking-address"`
+	RedistributionAddress  string        `yaml:"redistribution-address"`
+	PriceOracleAddress     string        `yaml:"price-oracle-address"`
+	SwapFactoryAddress     string        `yaml:"swap-factory-address"`
+}
+
+// LoadNetworkProfile reads a network profile from a YAML or JSON file. The
+// keys are the same as the yaml tags of NetworkProfile.
+func LoadNetworkProfile(path string) (*NetworkProfile, error) {
+	p := &NetworkProfile{}
+	if err := loadOptionsFile(path, p); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Validate checks that the profile is complete enough to start a node.
+func (p *NetworkProfile) Validate() error {
+	if p.NetworkID == 0 {
+		return errors.New("network profile: network id not provided")
+	}
+	if p.ChainID <= 0 {
+		return errors.New("network profile: chain id not provided")
+	}
+	if p.BlockTime < 0 {
+		return fmt.Errorf("network profile: invalid block time %s", p.BlockTime)
+	}
+	if p.PostageStampAddress == "" {
+		return errors.New("network profile: postage stamp address not provided")
+	}
+	if p.PostageStampStartBlock == 0 {
+		return errors.New("network profile: postage stamp start block not provided")
+	}
+	for name, addr := range map[string]string{
+		"postage stamp":  p.PostageStampAddress,
+		"staking":        p.StakingAddress,
+		"redistribution": p.RedistributionAddress,
+		"price oracle":   p.PriceOracleAddress,
+		"swap factory":   p.SwapFactoryAddress,
+	} {
+		if addr != "" && !common.IsHexAddress(addr) {
+			return fmt.Errorf("network profile: malformed %s address", name)
+		}
+	}
+	return nil
+}
+
+// networkProfile returns the custom network profile of the options, reading
+// it from NetworkProfileFile if it was not set directly.
+func (lo *LiteOptions) networkProfile() (*NetworkProfile, error) {
+	if lo.Network != nil || lo.NetworkProfileFile == "" {
+		return lo.Network, nil
+	}
+	return LoadNetworkProfile(lo.NetworkProfileFile)
+}
+
+// withNetwork returns a copy of the options with the values of the custom
+// network profile filled in. Options set explicitly take precedence over the
+// profile.
+func (lo *LiteOptions) withNetwork(p *NetworkProfile) *LiteOptions {
+	o := *lo
+	o.Network = p
+	o.NetworkID = valueOrDefault(o.NetworkID, p.NetworkID)
+	if o.Bootnodes == nil {
+		o.Bootnodes = p.Bootnodes
+	}
+	o.BlockTime = valueOrDefault(o.BlockTime, p.BlockTime)
+	if o.PostageContractAddress == "" {
+		o.PostageContractAddress = p.PostageStampAddress
+		o.PostageContractStartBlock = p.PostageStampStartBlock
+	}
+	o.StakingContractAddress = valueOrDefault(o.StakingContractAddress, p.StakingAddress)
+	o.RedistributionContractAddress = valueOrDefault(o.RedistributionContractAddress, p.RedistributionAddress)
+	o.PriceOracleAddress = valueOrDefault(o.PriceOracleAddress, p.PriceOracleAddress)
+	o.SwapFactoryAddress = valueOrDefault(o.SwapFactoryAddress, p.SwapFactoryAddress)
+	return &o
+}
+
+// customChainConfig is used for chains unknown to bee. Private swarms deploy
+// the mainnet contracts, so their ABIs are used, the addresses come from the
+// options.
+func customChainConfig(chainID int64, networkID uint64) chaincfg.ChainConfig {
+	cfg := chaincfg.Mainnet
+	cfg.ChainID = chainID
+	cfg.NetworkID = networkID
+	cfg.PostageStampStartBlock = 0
+	cfg.StakingAddress = common.Address{}
+	cfg.PostageStampAddress = common.Address{}
+	cfg.RedistributionAddress = common.Address{}
+	cfg.SwapPriceOracleAddress = common.Address{}
+	cfg.CurrentFactoryAddress = common.Address{}
+	return cfg
+}
+
+// verifyContracts checks that the contract addresses configured in the
+// options point to deployed contracts on the connected chain and that the
+// postage stamp start block is not in the future.
+func verifyContracts(ctx context.Context, backend transaction.Backend, o *node.Options) error {
+	for name, addr := range map[string]string{
+		"postage stamp":  o.PostageContractAddress,
+		"staking":        o.StakingContractAddress,
+		"redistribution": o.RedistributionContractAddress,
+		"price oracle":   o.PriceOracleAddress,
+		"swap factory":   o.SwapFactoryAddress,
+	} {
+		if addr == "" {
+			continue
+		}
+		code, err := backend.CodeAt(ctx, common.HexToAddress(addr), nil)
+		if err != nil {
+			return fmt.Errorf("%s contract %s: %w", name, addr, err)
+		}
+		if len(code) == 0 {
+			return fmt.Errorf("no %s contract deployed at %s on the connected chain", name, addr)
+		}
+	}
+
+	if o.PostageContractAddress != "" {
+		head, err := backend.BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("chain head: %w", err)
+		}
+		if o.PostageContractStartBlock > head {
+			return fmt.Errorf("postage stamp start block %d is ahead of the chain head %d", o.PostageContractStartBlock, head)
+		}
+	}
+	return nil
+}
+
+// initChain is node.InitChain connecting to the blockchain endpoint with the
+// HTTP client of the node.
+func initChain(
+	ctx context.Context,
+	logger log.Logger,
+	stateStore storage.StateStorer,
+	endpoint string,
+	oChainID int64,
+	signer crypto.Signer,
+	pollingInterval time.Duration,
+	chainEnabled bool,
+	client *http.Client,
+) (transaction.Backend, common.Address, int64, transaction.Monitor, transaction.Service, error) {
+	if !chainEnabled {
+		return node.InitChain(ctx, logger, stateStore, endpoint, oChainID, signer, pollingInterval, chainEnabled)
+	}
+
+	rpcClient, err := dialRPC(ctx, endpoint, client)
+	if err != nil {
+		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("dial blockchain client: %w", err)
+	}
+
+	var versionString string
+	if err := rpcClient.CallContext(ctx, &versionString, "web3_clientVersion"); err != nil {
+		rpcClient.Close()
+		logger.Info("could not connect to backend; in a swap-enabled network a working blockchain node (for xdai network in production, sepolia in testnet) is required; check your node or specify another node using BlockchainRpcEndpoint.", "backend_endpoint", endpoint)
+		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("blockchain client get version: %w", err)
+	}
+	logger.Info("connected to blockchain backend", "version", versionString)
+
+	backend := wrapped.NewBackend(ethclient.NewClient(rpcClient))
+
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("get chain id: %w", err)
+	}
+
+	overlayEthAddress, err := signer.EthereumAddress()
+	if err != nil {
+		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("blockchain address: %w", err)
+	}
+
+	transactionMonitor := transaction.NewMonitor(logger, backend, overlayEthAddress, pollingInterval, cancellationDepth)
+
+	transactionService, err := transaction.NewService(logger, overlayEthAddress, backend, signer, stateStore, chainID, transactionMonitor)
+	if err != nil {
+		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("new transaction service: %w", err)
+	}
+
+	return backend, overlayEthAddress, chainID.Int64(), transactionMonitor, transactionService, nil
+}
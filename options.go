@@ -0,0 +1,361 @@
+package beelite
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultAPIAddr                 = ":1633"
+	defaultP2PAddr                 = ":1634"
+	defaultTracingEndpoint         = ":6831"
+	defaultPaymentTolerance        = int64(25)
+	defaultPaymentEarly            = int64(50)
+	defaultStatestoreCacheCapacity = uint64(1_000_000)
+
+	// EnvPrefix is the prefix of the environment variables read by LoadLiteOptions.
+	// The variable name is the upper cased option key with dashes replaced by
+	// underscores, e.g. BEELITE_PAYMENT_THRESHOLD.
+	EnvPrefix = "BEELITE_"
+)
+
+// LoadLiteOptions reads the options from a YAML or JSON file, if path is not
+// empty, and overrides them with the values of the environment variables
+// prefixed with EnvPrefix. Keys are the yaml tags of the LiteOptions fields.
+// Durations are given as strings like "5s" and lists as arrays in files or as
+// comma separated values in environment variables.
+func LoadLiteOptions(path string) (*LiteOptions, error) {
+	lo := &LiteOptions{}
+
+	if path != "" {
+		if err := loadOptionsFile(path, lo); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, f := range optionFields(lo) {
+		name := EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setOption(f, value); err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", name, err)
+		}
+	}
+
+	network, err := lo.networkProfile()
+	if err != nil {
+		return nil, err
+	}
+	lo.Network = network
+
+	if err := lo.Validate(); err != nil {
+		return nil, err
+	}
+	return lo, nil
+}
+
+// Validate checks the options for values which NewBee would refuse.
+func (lo *LiteOptions) Validate() error {
+	if lo.BootnodeMode && !lo.FullNodeMode {
+		return errors.New("boot node must be started as a full node")
+	}
+
+	if lo.Mainnet && lo.NetworkID != chaincfg.Mainnet.NetworkID {
+		return errors.New("provided network ID does not match mainnet")
+	}
+
+	if lo.Network != nil {
+		if err := lo.Network.Validate(); err != nil {
+			return err
+		}
+		if lo.Mainnet {
+			return errors.New("custom network profile cannot be used on mainnet")
+		}
+		if lo.NetworkID != 0 && lo.NetworkID != lo.Network.NetworkID {
+			return errors.New("provided network ID does not match the network profile")
+		}
+		// full nodes need the staking contract of the network for the reserve
+		if lo.FullNodeMode && lo.StakingContractAddress == "" && lo.Network.StakingAddress == "" {
+			return errors.New("network profile: staking address not provided for a full node")
+		}
+	}
+
+	staticNodes, err := parseStaticNodes(lo.StaticNodes)
+	if err != nil {
+		return err
+	}
+	if len(staticNodes) > 0 && !lo.BootnodeMode {
+		return errors.New("static nodes can only be configured on bootnodes")
+	}
+
+	if !lo.FullNodeMode && lo.ReserveCapacityDoubling != 0 {
+		return errors.New("reserve capacity doubling is only allowed for full nodes")
+	}
+	if lo.ReserveCapacityDoubling < 0 || lo.ReserveCapacityDoubling > maxAllowedDoubling {
+		return fmt.Errorf("config reserve capacity doubling has to be between default: 0 and maximum: %d", maxAllowedDoubling)
+	}
+
+	paymentThreshold, ok := new(big.Int).SetString(lo.PaymentThreshold, 10)
+	if !ok {
+		return fmt.Errorf("invalid payment threshold: %s", lo.PaymentThreshold)
+	}
+	if paymentThreshold.Cmp(big.NewInt(minPaymentThreshold)) < 0 {
+		return fmt.Errorf("payment threshold below minimum generally accepted value, need at least %d", minPaymentThreshold)
+	}
+	if paymentThreshold.Cmp(big.NewInt(maxPaymentThreshold)) > 0 {
+		return fmt.Errorf("payment threshold above maximum generally accepted value, needs to be reduced to at most %d", maxPaymentThreshold)
+	}
+
+	if lo.PaymentTolerance != nil && *lo.PaymentTolerance < 0 {
+		return fmt.Errorf("invalid payment tolerance: %d", *lo.PaymentTolerance)
+	}
+	if lo.PaymentEarly != nil && (*lo.PaymentEarly > 100 || *lo.PaymentEarly < 0) {
+		return fmt.Errorf("invalid payment early: %d", *lo.PaymentEarly)
+	}
+
+	for name, addr := range map[string]string{
+		"postage stamp":    lo.PostageContractAddress,
+		"staking contract": lo.StakingContractAddress,
+		"redistribution":   lo.RedistributionContractAddress,
+		"swap factory":     lo.SwapFactoryAddress,
+		"price oracle":     lo.PriceOracleAddress,
+	} {
+		if addr != "" && !common.IsHexAddress(addr) {
+			return fmt.Errorf("malformed %s address", name)
+		}
+	}
+	if lo.PostageContractAddress != "" && lo.PostageContractStartBlock == 0 {
+		return errors.New("postage contract start block option not provided")
+	}
+
+	if lo.TargetNeighborhood != "" {
+		if _, err := swarm.ParseBitStrAddress(lo.TargetNeighborhood); err != nil {
+			return fmt.Errorf("invalid neighborhood. %s", lo.TargetNeighborhood)
+		}
+	}
+	if lo.NeighborhoodSuggester != "" && lo.NeighborhoodSuggester != NeighborhoodSuggesterDisabled {
+		if _, err := url.ParseRequestURI(lo.NeighborhoodSuggester); err != nil {
+			return fmt.Errorf("invalid neighborhood suggester: %w", err)
+		}
+	}
+	sources := 0
+	for _, set := range []bool{lo.PostageSnapshotGetter != nil, lo.PostageSnapshotFile != "", lo.PostageSnapshotURL != "", lo.PostageSnapshotFeed != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.New("only one postage snapshot source can be configured")
+	}
+	if lo.PostageSnapshotURL != "" {
+		if _, err := url.ParseRequestURI(lo.PostageSnapshotURL); err != nil {
+			return fmt.Errorf("invalid postage snapshot url: %w", err)
+		}
+		if sum, err := hex.DecodeString(lo.PostageSnapshotChecksum); err != nil || len(sum) != sha256.Size {
+			return errors.New("postage snapshot url needs the sha256 checksum of the snapshot")
+		}
+	}
+	for _, delta := range lo.PostageSnapshotDeltas {
+		if isSnapshotURL(delta) {
+			if _, err := url.ParseRequestURI(delta); err != nil {
+				return fmt.Errorf("invalid postage snapshot delta url: %w", err)
+			}
+		}
+	}
+	for _, signer := range lo.PostageSnapshotSigners {
+		if !common.IsHexAddress(signer) {
+			return fmt.Errorf("malformed postage snapshot signer address %q", signer)
+		}
+	}
+	if lo.PostageSnapshotFeed != "" {
+		if _, err := swarm.ParseHexAddress(lo.PostageSnapshotFeed); err != nil {
+			return fmt.Errorf("invalid postage snapshot feed: %w", err)
+		}
+		// full nodes sync the batches before they connect to the network
+		if lo.FullNodeMode {
+			return errors.New("postage snapshot feed is only supported by light nodes")
+		}
+	}
+	if lo.HTTPProxy != "" {
+		if _, err := url.ParseRequestURI(lo.HTTPProxy); err != nil {
+			return fmt.Errorf("invalid http proxy: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func parseStaticNodes(nodes []string) ([]swarm.Address, error) {
+	staticNodes := make([]swarm.Address, 0, len(nodes))
+	for _, p := range nodes {
+		addr, err := swarm.ParseHexAddress(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid swarm address %q configured for static node", p)
+		}
+		staticNodes = append(staticNodes, addr)
+	}
+	return staticNodes, nil
+}
+
+func valueOrDefault[T comparable](value, def T) T {
+	var zero T
+	if value == zero {
+		return def
+	}
+	return value
+}
+
+// pointerOrDefault returns the value of an option which can be set to its zero
+// value, def if it is not set.
+func pointerOrDefault[T any](value *T, def T) T {
+	if value == nil {
+		return def
+	}
+	return *value
+}
+
+// loadOptionsFile sets the fields of the struct pointed to by dst from the
+// values of a YAML or JSON file.
+func loadOptionsFile(path string, dst interface{}) error {
+	values, err := readOptionsFile(path)
+	if err != nil {
+		return err
+	}
+	fields := optionFields(dst)
+	for key, value := range values {
+		f, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("%s: unknown option %q", path, key)
+		}
+		if err := setOption(f, value); err != nil {
+			return fmt.Errorf("%s: option %q: %w", path, key, err)
+		}
+	}
+	return nil
+}
+
+func readOptionsFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read options file: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		d := json.NewDecoder(strings.NewReader(string(data)))
+		d.UseNumber()
+		err = d.Decode(&values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("unsupported options file extension %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse options file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// optionFields maps the yaml keys of the fields of the struct pointed to by
+// dst to the settable fields.
+func optionFields(dst interface{}) map[string]reflect.Value {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+	fields := make(map[string]reflect.Value, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = v.Field(i)
+	}
+	return fields
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setOption(f reflect.Value, value interface{}) error {
+	if f.Kind() == reflect.Pointer {
+		v := reflect.New(f.Type().Elem())
+		if err := setOption(v.Elem(), value); err != nil {
+			return err
+		}
+		f.Set(v)
+		return nil
+	}
+	if f.Kind() == reflect.Slice {
+		var items []string
+		switch v := value.(type) {
+		case []interface{}:
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+		case string:
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		case nil:
+		default:
+			return fmt.Errorf("expected a list, got %T", value)
+		}
+		f.Set(reflect.ValueOf(items))
+		return nil
+	}
+
+	s := fmt.Sprint(value)
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported option type %s", f.Type())
+	}
+	return nil
+}
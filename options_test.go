@@ -0,0 +1,113 @@
+package beelite
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLoadLiteOptions(t *testing.T) {
+	int64Ptr := func(v int64) *int64 { return &v }
+
+	for _, tc := range []struct {
+		name     string
+		filename string
+		file     string
+		env      map[string]string
+		want     LiteOptions
+	}{
+		{
+			name:     "file only",
+			filename: "options.yaml",
+			file: `data-dir: /data
+payment-threshold: "13500000"
+bootnodes: [/dnsaddr/a, /dnsaddr/b]
+block-time: 5s
+payment-tolerance-percent: 30
+`,
+			want: LiteOptions{
+				DataDir:          "/data",
+				PaymentThreshold: "13500000",
+				Bootnodes:        []string{"/dnsaddr/a", "/dnsaddr/b"},
+				BlockTime:        5 * time.Second,
+				PaymentTolerance: int64Ptr(30),
+			},
+		},
+		{
+			name:     "env overrides file",
+			filename: "options.json",
+			file:     `{"data-dir": "/data", "payment-threshold": "13500000", "bootnodes": ["/dnsaddr/a"], "payment-early-percent": 40}`,
+			env: map[string]string{
+				"BEELITE_DATA_DIR":              "/env",
+				"BEELITE_BOOTNODES":             "/dnsaddr/c, /dnsaddr/d",
+				"BEELITE_PAYMENT_EARLY_PERCENT": "60",
+			},
+			want: LiteOptions{
+				DataDir:          "/env",
+				PaymentThreshold: "13500000",
+				Bootnodes:        []string{"/dnsaddr/c", "/dnsaddr/d"},
+				PaymentEarly:     int64Ptr(60),
+			},
+		},
+		{
+			name:     "explicit zero",
+			filename: "options.yml",
+			file: `payment-threshold: "13500000"
+payment-tolerance-percent: 0
+`,
+			env: map[string]string{
+				"BEELITE_PAYMENT_EARLY_PERCENT": "0",
+			},
+			want: LiteOptions{
+				PaymentThreshold: "13500000",
+				PaymentTolerance: int64Ptr(0),
+				PaymentEarly:     int64Ptr(0),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.filename)
+			if err := os.WriteFile(path, []byte(tc.file), 0600); err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+
+			lo, err := LoadLiteOptions(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lo.DataDir != tc.want.DataDir {
+				t.Errorf("got data dir %q, want %q", lo.DataDir, tc.want.DataDir)
+			}
+			if lo.PaymentThreshold != tc.want.PaymentThreshold {
+				t.Errorf("got payment threshold %q, want %q", lo.PaymentThreshold, tc.want.PaymentThreshold)
+			}
+			if !slices.Equal(lo.Bootnodes, tc.want.Bootnodes) {
+				t.Errorf("got bootnodes %v, want %v", lo.Bootnodes, tc.want.Bootnodes)
+			}
+			if lo.BlockTime != tc.want.BlockTime {
+				t.Errorf("got block time %s, want %s", lo.BlockTime, tc.want.BlockTime)
+			}
+			for _, p := range []struct {
+				name      string
+				got, want *int64
+			}{
+				{name: "payment tolerance", got: lo.PaymentTolerance, want: tc.want.PaymentTolerance},
+				{name: "payment early", got: lo.PaymentEarly, want: tc.want.PaymentEarly},
+			} {
+				switch {
+				case p.want == nil && p.got != nil:
+					t.Errorf("got %s %d, want unset", p.name, *p.got)
+				case p.want != nil && p.got == nil:
+					t.Errorf("got %s unset, want %d", p.name, *p.want)
+				case p.want != nil && *p.got != *p.want:
+					t.Errorf("got %s %d, want %d", p.name, *p.got, *p.want)
+				}
+			}
+		})
+	}
+}
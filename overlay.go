@@ -0,0 +1,175 @@
+package beelite
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/util/ioutil"
+)
+
+const (
+	// mineProgressInterval is the number of nonces tried between two calls
+	// of the MineProgressFunc.
+	mineProgressInterval = 1 << 16
+	// overlayChangedKey marks an overlay replaced by MineOverlay, so that the
+	// next start resets the reserve and updates the staking contract.
+	overlayChangedKey = "beelite_overlay_changed"
+)
+
+var (
+	ErrOverlayChangeRejected = errors.New("overlay address change rejected")
+	errInvalidNeighborhood   = errors.New("invalid neighborhood")
+)
+
+// OverlayChangeConfirmFunc is asked before an existing overlay address is
+// replaced by one mined for the target neighborhood. The state of the old
+// neighborhood is cleared if it returns true.
+type OverlayChangeConfirmFunc func(oldOverlay, newOverlay swarm.Address) bool
+
+// MineProgressFunc is called periodically while an overlay address is mined
+// with the number of nonces tried so far.
+type MineProgressFunc func(attempts uint64)
+
+// MineOverlay mines an overlay address in the target neighborhood, given as a
+// bit string, for the swarm key of keys and stores it in dataDir, so that the
+// next Start uses it. Mining stops when ctx is canceled. If the node had an
+// overlay address before, the peers and the state of the old neighborhood are
+// cleared and the reserve is reset on the next Start. The node must not be
+// running.
+func MineOverlay(ctx context.Context, dataDir string, keys KeyProvider, networkID uint64, target string, progress MineProgressFunc) (swarm.Address, error) {
+	if dataDir == "" {
+		return swarm.ZeroAddress, errDataDirRequired
+	}
+	signer, err := keys.Signer()
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	pubKey, err := signer.PublicKey()
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("swarm public key: %w", err)
+	}
+
+	stateStore, err := openStateStore(dataDir)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("init state store: %w", err)
+	}
+	defer stateStore.Close()
+
+	nonce, nonceExists, err := overlayNonceExists(stateStore)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("check presence of nonce: %w", err)
+	}
+	overlay, err := crypto.NewOverlayAddress(*pubKey, networkID, nonce)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("compute overlay address: %w", err)
+	}
+	if ok, err := inNeighborhood(overlay, target); err != nil || ok {
+		return overlay, err
+	}
+
+	newOverlay, newNonce, err := mineOverlay(ctx, *pubKey, networkID, target, progress)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("mine overlay address: %w", err)
+	}
+
+	if nonceExists {
+		if err := clearNeighborhood(dataDir, stateStore); err != nil {
+			return swarm.ZeroAddress, err
+		}
+		if err := stateStore.Put(overlayChangedKey, true); err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("statestore: mark overlay change: %w", err)
+		}
+	}
+	if err := setOverlay(stateStore, newOverlay, newNonce); err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("statestore: save new overlay: %w", err)
+	}
+	return newOverlay, nil
+}
+
+// NewFileKeyProvider returns the default KeyProvider of a node, with the file
+// keystore in dataDir, e.g. to mine its overlay address before Start.
+func NewFileKeyProvider(dataDir, password string) KeyProvider {
+	return NewKeystoreKeyProvider(filekeystore.New(filepath.Join(dataDir, keysDir)), password)
+}
+
+// inNeighborhood reports whether the overlay is in the neighborhood given as
+// a bit string.
+func inNeighborhood(overlay swarm.Address, target string) (bool, error) {
+	neighborhood, err := swarm.ParseBitStrAddress(target)
+	if err != nil {
+		return false, fmt.Errorf("%w. %s", errInvalidNeighborhood, target)
+	}
+	return swarm.Proximity(overlay.Bytes(), neighborhood.Bytes()) >= uint8(len(target)), nil
+}
+
+// mineOverlay tries nonces until the overlay address is in the target
+// neighborhood, the same way as the bee miner, reporting the progress.
+func mineOverlay(ctx context.Context, p ecdsa.PublicKey, networkID uint64, target string, progress MineProgressFunc) (swarm.Address, []byte, error) {
+	neighborhood, err := swarm.ParseBitStrAddress(target)
+	if err != nil {
+		return swarm.ZeroAddress, nil, fmt.Errorf("%w. %s", errInvalidNeighborhood, target)
+	}
+	prox := uint8(len(target))
+
+	nonce := make([]byte, 32)
+	for i := uint64(0); ; i++ {
+		if i%mineProgressInterval == 0 {
+			select {
+			case <-ctx.Done():
+				return swarm.ZeroAddress, nil, ctx.Err()
+			default:
+			}
+			if progress != nil && i > 0 {
+				progress(i)
+			}
+		}
+
+		binary.LittleEndian.PutUint64(nonce, i)
+		overlay, err := crypto.NewOverlayAddress(p, networkID, nonce)
+		if err != nil {
+			return swarm.ZeroAddress, nil, fmt.Errorf("compute overlay address: %w", err)
+		}
+		if swarm.Proximity(overlay.Bytes(), neighborhood.Bytes()) >= prox {
+			return overlay, nonce, nil
+		}
+	}
+}
+
+// clearNeighborhood removes the peers and the state which belong to the
+// neighborhood of the previous overlay address.
+func clearNeighborhood(dataDir string, stateStore storage.StateStorerManager) error {
+	if err := ioutil.RemoveContent(filepath.Join(dataDir, ioutil.DataPathKademlia)); err != nil {
+		return fmt.Errorf("delete %s: %w", ioutil.DataPathKademlia, err)
+	}
+	if err := stateStore.ClearForHopping(); err != nil {
+		return fmt.Errorf("clearing stateStore %w", err)
+	}
+	return nil
+}
+
+// overlayChanged reports the mark left by MineOverlay. The mark is kept until
+// clearOverlayChanged, so that a failed start handles the change again.
+func overlayChanged(s storage.StateStorer) (bool, error) {
+	var changed bool
+	if err := s.Get(overlayChangedKey, &changed); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return changed, nil
+}
+
+// clearOverlayChanged removes the mark once the reserve is reset and the
+// overlay is changed in the staking contract.
+func clearOverlayChanged(s storage.StateStorer) error {
+	return s.Delete(overlayChangedKey)
+}
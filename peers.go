@@ -0,0 +1,143 @@
+package beelite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/topology"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+var errInvalidMultiaddr = errors.New("invalid multiaddress")
+
+// PeerInfo describes a connected peer as seen by the local node.
+type PeerInfo struct {
+	Overlay   swarm.Address
+	Underlays []string
+	Bin       uint8
+	FullNode  bool
+	Direction string
+	Latency   time.Duration
+	Healthy   bool
+}
+
+// BlocklistedPeer describes a peer currently on the local blocklist.
+type BlocklistedPeer struct {
+	Overlay  swarm.Address
+	FullNode bool
+	Reason   string
+	Duration time.Duration
+}
+
+// Peers returns the currently connected peers. Direction, latency and health
+// are taken from the kademlia metrics collected by the pingpong health checks.
+func (bl *Beelite) Peers() []PeerInfo {
+	metrics := make(map[string]*topology.MetricSnapshotView)
+	for _, bin := range kadBins(bl.topologyDriver.Snapshot()) {
+		for _, p := range bin.ConnectedPeers {
+			metrics[p.Address.ByteString()] = p.Metrics
+		}
+	}
+
+	peers := bl.p2pService.Peers()
+	infos := make([]PeerInfo, 0, len(peers))
+	for _, p := range peers {
+		info := PeerInfo{
+			Overlay:  p.Address,
+			Bin:      swarm.Proximity(bl.overlay.Bytes(), p.Address.Bytes()),
+			FullNode: p.FullNode,
+		}
+
+		bzzAddr, err := bl.addressBook.Get(p.Address)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			bl.logger.Debug("peers: addressbook lookup failed", "peer_address", p.Address, "error", err)
+		}
+		if bzzAddr != nil && bzzAddr.Underlay != nil {
+			info.Underlays = []string{bzzAddr.Underlay.String()}
+		}
+
+		if m := metrics[p.Address.ByteString()]; m != nil {
+			info.Direction = m.SessionConnectionDirection
+			info.Latency = time.Duration(m.LatencyEWMA) * time.Millisecond
+			info.Healthy = m.Healthy
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// ConnectPeer dials the given underlay multiaddress and adds the peer to the topology.
+func (bl *Beelite) ConnectPeer(ctx context.Context, multiaddr string) (swarm.Address, error) {
+	addr, err := ma.NewMultiaddr(multiaddr)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %w", errInvalidMultiaddr, err)
+	}
+
+	bzzAddr, err := bl.p2pService.Connect(ctx, addr)
+	if err != nil {
+		bl.logger.Debug("p2p connect failed", "addresses", addr, "error", err)
+		return swarm.ZeroAddress, fmt.Errorf("p2p connect: %w", err)
+	}
+
+	if err := bl.topologyDriver.Connected(ctx, p2p.Peer{Address: bzzAddr.Overlay}, true); err != nil {
+		_ = bl.p2pService.Disconnect(bzzAddr.Overlay, "failed to notify topology")
+		bl.logger.Debug("connect to peer failed", "addresses", addr, "error", err)
+		return swarm.ZeroAddress, fmt.Errorf("notify topology: %w", err)
+	}
+
+	return bzzAddr.Overlay, nil
+}
+
+// DisconnectPeer closes the connection to the given peer. It returns
+// p2p.ErrPeerNotFound if the peer is not connected.
+func (bl *Beelite) DisconnectPeer(overlay swarm.Address) error {
+	return bl.p2pService.Disconnect(overlay, "user requested disconnect")
+}
+
+// Blocklist disconnects the peer and blocks in and outbound connections to it
+// for the given duration. A zero duration blocks the peer indefinitely.
+func (bl *Beelite) Blocklist(overlay swarm.Address, duration time.Duration, reason string) error {
+	return bl.p2pService.Blocklist(overlay, duration, reason)
+}
+
+// Blocklisted returns the peers currently on the blocklist.
+func (bl *Beelite) Blocklisted() ([]BlocklistedPeer, error) {
+	peers, err := bl.p2pService.BlocklistedPeers()
+	if err != nil {
+		return nil, fmt.Errorf("get blocklisted peers: %w", err)
+	}
+
+	out := make([]BlocklistedPeer, 0, len(peers))
+	for _, p := range peers {
+		out = append(out, BlocklistedPeer{
+			Overlay:  p.Address,
+			FullNode: p.FullNode,
+			Reason:   p.Reason,
+			Duration: p.Duration,
+		})
+	}
+	return out, nil
+}
+
+// Ping measures the round trip time to a connected peer using the pingpong protocol.
+func (bl *Beelite) Ping(ctx context.Context, overlay swarm.Address) (time.Duration, error) {
+	return bl.pingpong.Ping(ctx, overlay, "ping")
+}
+
+// kadBins flattens the fixed size bin struct of the kademlia snapshot.
+func kadBins(params *topology.KadParams) []topology.BinInfo {
+	b := params.Bins
+	return []topology.BinInfo{
+		b.Bin0, b.Bin1, b.Bin2, b.Bin3, b.Bin4, b.Bin5, b.Bin6, b.Bin7,
+		b.Bin8, b.Bin9, b.Bin10, b.Bin11, b.Bin12, b.Bin13, b.Bin14, b.Bin15,
+		b.Bin16, b.Bin17, b.Bin18, b.Bin19, b.Bin20, b.Bin21, b.Bin22, b.Bin23,
+		b.Bin24, b.Bin25, b.Bin26, b.Bin27, b.Bin28, b.Bin29, b.Bin30, b.Bin31,
+	}
+}
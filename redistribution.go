@@ -0,0 +1,92 @@
+package beelite
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+)
+
+var ErrRedistributionDisabled = errors.New("storage incentives are not enabled on this node")
+
+// RedistributionStatus is the state of the node in the redistribution game.
+type RedistributionStatus struct {
+	Round             uint64
+	Phase             string
+	Block             uint64
+	LastWonRound      uint64
+	LastPlayedRound   uint64
+	LastFrozenRound   uint64
+	LastSelectedRound uint64
+	Reward            *big.Int
+	Fees              *big.Int
+	SampleDuration    time.Duration
+	IsFrozen          bool
+	IsFullySynced     bool
+	IsHealthy         bool
+	Paused            bool
+}
+
+// redistributionControl gates the participation of the storage incentives
+// agent in the redistribution game. The agent samples the returned value of
+// participate at the beginning of every phase and skips the round if it is false.
+type redistributionControl struct {
+	paused        atomic.Bool
+	isFullySynced func() bool
+}
+
+func (r *redistributionControl) participate() bool {
+	return !r.paused.Load() && r.isFullySynced()
+}
+
+// RedistributionStatus returns the state of the storage incentives agent.
+func (bl *Beelite) RedistributionStatus() (*RedistributionStatus, error) {
+	if bl.redistributionAgent == nil {
+		return nil, ErrRedistributionDisabled
+	}
+
+	status, err := bl.redistributionAgent.Status()
+	if err != nil {
+		return nil, fmt.Errorf("redistribution status: %w", err)
+	}
+
+	return &RedistributionStatus{
+		Round:             status.Round,
+		Phase:             status.Phase.String(),
+		Block:             status.Block,
+		LastWonRound:      status.LastWonRound,
+		LastPlayedRound:   status.LastPlayedRound,
+		LastFrozenRound:   status.LastFrozenRound,
+		LastSelectedRound: status.LastSelectedRound,
+		Reward:            status.Reward,
+		Fees:              status.Fees,
+		SampleDuration:    status.SampleDuration,
+		IsFrozen:          status.IsFrozen,
+		IsFullySynced:     bl.redistribution.isFullySynced(),
+		IsHealthy:         status.IsHealthy,
+		Paused:            bl.redistribution.paused.Load(),
+	}, nil
+}
+
+// PauseRedistribution stops the node from participating in the redistribution
+// game. It takes effect from the next phase, a commit already sent is still
+// revealed and claimed.
+func (bl *Beelite) PauseRedistribution() error {
+	if bl.redistributionAgent == nil {
+		return ErrRedistributionDisabled
+	}
+	bl.redistribution.paused.Store(true)
+	bl.logger.Info("redistribution participation paused")
+	return nil
+}
+
+// ResumeRedistribution lets the node participate in the redistribution game again.
+func (bl *Beelite) ResumeRedistribution() error {
+	if bl.redistributionAgent == nil {
+		return ErrRedistributionDisabled
+	}
+	bl.redistribution.paused.Store(false)
+	bl.logger.Info("redistribution participation resumed")
+	return nil
+}
@@ -0,0 +1,297 @@
+package beelite
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
+	"github.com/ethersphere/bee/v2/pkg/file/joiner"
+	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/manifest"
+	"github.com/ethersphere/bee/v2/pkg/manifest/mantaray"
+	"github.com/ethersphere/bee/v2/pkg/storer"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// maxManifestNodeSize is the size up to which re-uploaded content is checked
+// to be a manifest, larger content is streamed as it is.
+const maxManifestNodeSize = 256 * swarm.ChunkSize
+
+// rotateRetrieveTimeout is the time the root chunk of a reference decrypted
+// with an access key of the history is looked up in the network.
+const rotateRetrieveTimeout = 15 * time.Second
+
+var errRotateKeyNotFound = errors.New("reference cannot be decrypted with the access keys of the history")
+
+// RevokeAndReencrypt revokes the grantees like AddRevokeGrantees and uploads
+// the content of refsToRotate, references encrypted with the history at any
+// time, again with new encryption under the access key created by the
+// revoke. Only the new references are protected from the revoked grantees,
+// the old content stays in the network until its batch expires and the old
+// references still decrypt with the history entries before the revoke. The
+// files of a manifest are uploaded again too. It returns the new grantee list
+// and history and the new encrypted references by the old ones.
+func (bl *Beelite) RevokeAndReencrypt(
+	ctx context.Context,
+	batchHex string,
+	historyRef,
+	granteeRef swarm.Address,
+	revoke []string,
+	refsToRotate []swarm.Address,
+) (encryptedglRef swarm.Address, newHistoryRef swarm.Address, rotated map[string]string, err error) {
+	if len(revoke) == 0 {
+		err = errors.New("nothing to revoke")
+		return
+	}
+
+	// the references are decrypted before the revoke, with the access key
+	// they were encrypted with
+	entries, err := bl.ListActHistory(ctx, historyRef)
+	if err != nil {
+		return
+	}
+	decrypted := make([]swarm.Address, len(refsToRotate))
+	for i, ref := range refsToRotate {
+		decrypted[i], err = bl.decryptRotated(ctx, ref, historyRef, entries)
+		if err != nil {
+			err = fmt.Errorf("decrypt %s: %w", ref, err)
+			return
+		}
+	}
+
+	batch, err := hex.DecodeString(batchHex)
+	if err != nil {
+		err = errInvalidPostageBatch
+		return
+	}
+	putter, err := bl.newStamperPutter(ctx, putterOptions{
+		BatchID: batch,
+	})
+	if err != nil {
+		bl.logger.Error(err, "putter failed")
+		return
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, putter.Cleanup())
+		}
+	}()
+
+	// the content is uploaded again before the revoke, so that a failed
+	// upload leaves the grantees unchanged
+	references := make([]swarm.Address, len(refsToRotate))
+	for i, ref := range refsToRotate {
+		references[i], err = bl.reuploadContent(ctx, putter, decrypted[i])
+		if err != nil {
+			bl.logger.Error(err, "re-upload failed", "address", ref)
+			err = fmt.Errorf("re-upload %s: %w", ref, err)
+			return
+		}
+	}
+
+	encryptedglRef, newHistoryRef, err = bl.AddRevokeGrantees(ctx, batchHex, granteeRef, historyRef, nil, revoke)
+	if err != nil {
+		return
+	}
+
+	rotated = make(map[string]string, len(refsToRotate))
+	for i, ref := range refsToRotate {
+		var encryptedRef swarm.Address
+		encryptedRef, newHistoryRef, err = bl.actEncryptionHandler(ctx, putter, references[i], newHistoryRef)
+		if err != nil {
+			bl.logger.Error(err, "access control upload failed")
+			return
+		}
+		rotated[ref.String()] = encryptedRef.String()
+	}
+
+	// the session holds the uploads of all references, it has no single root
+	err = putter.Done(swarm.ZeroAddress)
+	if err != nil {
+		bl.logger.Error(err, "done split failed")
+		err = fmt.Errorf("done split failed: %w", err)
+		return
+	}
+	return
+}
+
+// decryptRotated decrypts the reference with the access key of the history
+// entry it was encrypted with. A wrong access key decrypts to a random
+// reference, so the entries are tried from the newest one until the root
+// chunk of the decrypted reference is found, in the local store first and
+// then in the network.
+func (bl *Beelite) decryptRotated(ctx context.Context, ref, historyRef swarm.Address, entries []ActHistoryEntry) (swarm.Address, error) {
+	var (
+		candidates []swarm.Address
+		seen       = make(map[string]bool)
+	)
+	for _, entry := range slices.Backward(entries) {
+		timestamp := entry.Timestamp
+		decrypted, err := bl.actDecryptionHandler(ctx, ref, bl.publicKey, &historyRef, &timestamp, true)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		// the access key only changes with a revoke
+		if seen[decrypted.String()] {
+			continue
+		}
+		seen[decrypted.String()] = true
+
+		found, err := bl.storer.ChunkStore().Has(ctx, rootAddress(decrypted))
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		if found {
+			return decrypted, nil
+		}
+		candidates = append(candidates, decrypted)
+	}
+
+	for _, decrypted := range candidates {
+		retrieveCtx, cancel := context.WithTimeout(ctx, rotateRetrieveTimeout)
+		_, err := bl.storer.Download(true).Get(retrieveCtx, rootAddress(decrypted))
+		cancel()
+		if err == nil {
+			return decrypted, nil
+		}
+		if ctx.Err() != nil {
+			return swarm.ZeroAddress, ctx.Err()
+		}
+	}
+	return swarm.ZeroAddress, errRotateKeyNotFound
+}
+
+// rootAddress returns the address of the root chunk of the reference, which
+// is followed by the decryption key for encrypted content.
+func rootAddress(reference swarm.Address) swarm.Address {
+	return swarm.NewAddress(reference.Bytes()[:swarm.HashSize])
+}
+
+// reuploadContent uploads the content of the reference again encrypted, and
+// the files of it too if it is a manifest, and returns the new reference.
+func (bl *Beelite) reuploadContent(ctx context.Context, putter storer.PutterSession, reference swarm.Address) (swarm.Address, error) {
+	reader, size, err := joiner.New(ctx, bl.storer.Download(true), bl.storer.Cache(), reference, redundancy.DefaultLevel)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	var r io.Reader = reader
+	if size <= maxManifestNodeSize {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		if new(mantaray.Node).UnmarshalBinary(data) == nil {
+			return bl.reuploadManifest(ctx, putter, reference)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	return requestPipelineFn(putter, true, redundancy.DefaultLevel)(ctx, r)
+}
+
+// reuploadManifest uploads the manifest again with the files of its entries
+// uploaded again. Entries protected with their own access control are
+// protected again with the same history.
+func (bl *Beelite) reuploadManifest(ctx context.Context, putter storer.PutterSession, reference swarm.Address) (swarm.Address, error) {
+	ls := loadsave.New(bl.storer.Download(true), bl.storer.Cache(), requestPipelineFactory(ctx, putter, true, redundancy.DefaultLevel), redundancy.DefaultLevel)
+	m, err := manifest.NewMantarayManifestReference(reference, ls)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	root, ok := m.(interface{ Root() *mantaray.Node })
+	if !ok {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %T", accesscontrol.ErrUnexpectedType, m)
+	}
+	newManifest, err := manifest.NewMantarayManifest(ls, true)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	// entries without content, like the metadata of a website or feed, are
+	// added after the files with an empty reference, as the zero reference of
+	// the old manifest may be shorter than the encrypted ones
+	var (
+		metadataEntries = make(map[string]manifest.Entry)
+		contentAdded    bool
+		// the entries of the histories of protected paths
+		histories = make(map[string][]ActHistoryEntry)
+	)
+	err = root.Root().WalkNode(ctx, []byte{}, ls, func(path []byte, node *mantaray.Node, err error) error {
+		if err != nil {
+			return err
+		}
+		if !node.IsValueType() || len(path) == 0 {
+			return nil
+		}
+		entry := swarm.NewAddress(node.Entry())
+		if entry.IsZero() || entry.IsEmpty() {
+			metadataEntries[string(path)] = manifest.NewEntry(entry, node.Metadata())
+			return nil
+		}
+
+		mtdt := maps.Clone(node.Metadata())
+		h, protected := mtdt[actHistoryMetadataKey]
+		if protected {
+			entry, err = bl.decryptRotatedEntry(ctx, entry, h, histories)
+			if err != nil {
+				return fmt.Errorf("entry %s: %w", path, err)
+			}
+		}
+		entry, err = bl.reuploadContent(ctx, putter, entry)
+		if err != nil {
+			return fmt.Errorf("entry %s: %w", path, err)
+		}
+		if protected {
+			access := &PathAccess{HistoryAddress: swarm.MustParseHexAddress(h)}
+			var actMtdt map[string]string
+			entry, actMtdt, err = bl.protectEntry(ctx, putter, access, entry)
+			if err != nil {
+				return fmt.Errorf("entry %s: %w", path, err)
+			}
+			maps.Copy(mtdt, actMtdt)
+		}
+		contentAdded = true
+		return newManifest.Add(ctx, string(path), manifest.NewEntry(entry, mtdt))
+	})
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	for path, entry := range metadataEntries {
+		if contentAdded {
+			entry = manifest.NewEntry(swarm.ZeroAddress, entry.Metadata())
+		}
+		if err := newManifest.Add(ctx, path, entry); err != nil {
+			return swarm.ZeroAddress, err
+		}
+	}
+
+	return newManifest.Store(ctx)
+}
+
+// decryptRotatedEntry decrypts the reference of a manifest entry protected by
+// its own access control with the entry of its history it was encrypted with,
+// the histories hold the entries of the histories already listed.
+func (bl *Beelite) decryptRotatedEntry(ctx context.Context, reference swarm.Address, historyHex string, histories map[string][]ActHistoryEntry) (swarm.Address, error) {
+	historyAddress, err := swarm.ParseHexAddress(historyHex)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("invalid act history of entry: %w", err)
+	}
+	entries, ok := histories[historyAddress.String()]
+	if !ok {
+		entries, err = bl.ListActHistory(ctx, historyAddress)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		histories[historyAddress.String()] = entries
+	}
+	return bl.decryptRotated(ctx, reference, historyAddress, entries)
+}
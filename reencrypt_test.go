@@ -0,0 +1,293 @@
+package beelite
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
+	"github.com/ethersphere/bee/v2/pkg/api"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	batchstoremock "github.com/ethersphere/bee/v2/pkg/postage/batchstore/mock"
+	postagemock "github.com/ethersphere/bee/v2/pkg/postage/mock"
+	statestoremock "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/storage/inmemstore"
+	"github.com/ethersphere/bee/v2/pkg/storer"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// newTestUploadBeelite returns a light node uploading to an in memory store,
+// the chunks pushed to the network are kept in its cache.
+func newTestUploadBeelite(t *testing.T) (*Beelite, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	st, err := storer.New(ctx, "", &storer.Options{CacheCapacity: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	go func() {
+		for {
+			select {
+			case op := <-st.PusherFeed():
+				op.Err <- st.Cache().Put(ctx, op.Chunk)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Beelite{
+		storer:        st,
+		accesscontrol: accesscontrol.NewController(accesscontrol.NewLogic(accesscontrol.NewDefaultSession(key))),
+		publicKey:     &key.PublicKey,
+		signer:        crypto.NewDefaultSigner(key),
+		logger:        log.Noop,
+		beeNodeMode:   api.LightMode,
+		batchStore:    batchstoremock.New(batchstoremock.WithAcceptAllExistsFunc()),
+		post:          postagemock.New(postagemock.WithAcceptAll()),
+		stamperStore:  inmemstore.New(),
+		stateStore:    statestoremock.NewStateStore(),
+	}, key
+}
+
+func testGrantee(t *testing.T) string {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&key.PublicKey))
+}
+
+// nextHistorySecond waits for the next second, the history has one entry per
+// second.
+func nextHistorySecond() {
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+}
+
+func TestRevokeAndReencrypt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bl, key := newTestUploadBeelite(t)
+	batch := hex.EncodeToString(make([]byte, 32))
+	grantee1, grantee2 := testGrantee(t), testGrantee(t)
+
+	// the first file is encrypted with the access key before the revoke of
+	// the first grantee, the second one with the access key after it
+	refA, history, err := bl.AddBytes(ctx, batch, true, swarm.ZeroAddress, false, redundancy.NONE, strings.NewReader("content before revoke"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextHistorySecond()
+	granteeRef, history, err := bl.CreateGrantees(ctx, batch, history, []string{grantee1, grantee2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextHistorySecond()
+	granteeRef, history, err = bl.AddRevokeGrantees(ctx, batch, granteeRef, history, nil, []string{grantee1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refB, history, err := bl.AddBytes(ctx, batch, true, history, false, redundancy.NONE, strings.NewReader("content after revoke"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextHistorySecond()
+
+	newGranteeRef, newHistory, rotated, err := bl.RevokeAndReencrypt(ctx, batch, history, granteeRef, []string{grantee2}, []swarm.Address{refA, refB})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grantees, err := bl.GetGranteeList(ctx, newGranteeRef, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(grantees, grantee2) {
+		t.Fatalf("revoked grantee in grantee list %v", grantees)
+	}
+
+	for _, tc := range []struct {
+		ref  swarm.Address
+		want string
+	}{
+		{ref: refA, want: "content before revoke"},
+		{ref: refB, want: "content after revoke"},
+	} {
+		ref, want := tc.ref, tc.want
+		rotatedRef, ok := rotated[ref.String()]
+		if !ok {
+			t.Fatalf("reference %s not rotated", ref)
+		}
+		newRef := swarm.MustParseHexAddress(rotatedRef)
+		reader, err := bl.GetBytes(ctx, newRef, &key.PublicKey, &newHistory, nil)
+		if err != nil {
+			t.Fatalf("get rotated reference of %s: %v", ref, err)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("got content %q, want %q", got, want)
+		}
+
+		// the content is encrypted again, so the old content reference
+		// cannot be derived from the new one
+		entries, err := bl.ListActHistory(ctx, history)
+		if err != nil {
+			t.Fatal(err)
+		}
+		oldContent, err := bl.decryptRotated(ctx, ref, history, entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+		newContent, err := bl.actDecryptionHandler(ctx, newRef, &key.PublicKey, &newHistory, nil, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if newContent.Equal(oldContent) {
+			t.Fatalf("content of %s not encrypted again", ref)
+		}
+	}
+}
+
+func TestRevokeAndReencryptUnknownReference(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bl, _ := newTestUploadBeelite(t)
+	batch := hex.EncodeToString(make([]byte, 32))
+	grantee := testGrantee(t)
+
+	_, history, err := bl.AddBytes(ctx, batch, true, swarm.ZeroAddress, false, redundancy.NONE, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextHistorySecond()
+	granteeRef, history, err := bl.CreateGrantees(ctx, batch, history, []string{grantee})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknown := swarm.RandAddress(t)
+	_, _, _, err = bl.RevokeAndReencrypt(ctx, batch, history, granteeRef, []string{grantee}, []swarm.Address{unknown})
+	if !errors.Is(err, errRotateKeyNotFound) {
+		t.Fatalf("got error %v, want %v", err, errRotateKeyNotFound)
+	}
+
+	// the grantee is not revoked if the content cannot be uploaded again
+	entries, err := bl.ListActHistory(ctx, history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grantees, err := bl.GetGranteeListAt(ctx, history, entries[len(entries)-1].Timestamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(grantees, grantee) {
+		t.Fatalf("grantee missing from grantee list %v", grantees)
+	}
+}
+
+func TestRevokeAndReencryptDirectory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bl, key := newTestUploadBeelite(t)
+	batch := hex.EncodeToString(make([]byte, 32))
+	grantee := testGrantee(t)
+
+	files := map[string]string{
+		"index.html":         "public index",
+		"private/index.html": "private index",
+	}
+	ref, history, access, err := bl.AddDirBzzWithAccess(ctx, batch, contentTypeTar, "index.html", "", true, swarm.ZeroAddress, []PathAccess{{Prefix: "private/"}}, false, redundancy.NONE, testTar(t, files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextHistorySecond()
+	granteeRef, history, err := bl.CreateGrantees(ctx, batch, history, []string{grantee})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextHistorySecond()
+
+	_, newHistory, rotated, err := bl.RevokeAndReencrypt(ctx, batch, history, granteeRef, []string{grantee}, []swarm.Address{ref})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newRef := swarm.MustParseHexAddress(rotated[ref.String()])
+
+	manifestRef, err := bl.actDecryptionHandler(ctx, newRef, &key.PublicKey, &newHistory, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := bl.loadBzzManifest(ctx, loadsave.NewReadonly(bl.storer.Download(true), bl.storer.Cache(), redundancy.DefaultLevel), manifestRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldManifestRef, err := bl.actDecryptionHandler(ctx, ref, &key.PublicKey, &history, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldManifest, err := bl.loadBzzManifest(ctx, loadsave.NewReadonly(bl.storer.Download(true), bl.storer.Cache(), redundancy.DefaultLevel), oldManifestRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path      string
+		want      string
+		protected bool
+	}{
+		{path: "", want: "public index"},
+		{path: "private", want: "private index", protected: true},
+	} {
+		reader, _, err := bl.GetBzzPath(ctx, newRef, tc.path, &key.PublicKey, &newHistory, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Fatalf("%s: got content %q, want %q", tc.path, got, tc.want)
+		}
+
+		// the file is uploaded again, a protected one with the history of its path
+		entry, err := lookupBzzPath(ctx, m, tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		oldEntry, err := lookupBzzPath(ctx, oldManifest, tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Reference().Equal(oldEntry.Reference()) {
+			t.Fatalf("%s: file not uploaded again", tc.path)
+		}
+		if h := entry.Metadata()[actHistoryMetadataKey]; tc.protected && h != access[0].HistoryAddress.String() {
+			t.Fatalf("%s: got history %s, want %s", tc.path, h, access[0].HistoryAddress)
+		}
+	}
+}
@@ -5,18 +5,26 @@
 package beelite
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"os"
 	"sort"
+	"strings"
 	"sync"
 
 	"slices"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	archive "github.com/ethersphere/batch-archive"
 	"github.com/ethersphere/bee/v2/pkg/log"
@@ -25,14 +33,177 @@ import (
 
 var _ listener.BlockHeightContractFilterer = (*SnapshotLogFilterer)(nil)
 
+var (
+	errSnapshotChecksum = errors.New("postage snapshot checksum mismatch")
+	errSnapshotClosed   = errors.New("postage snapshot closed")
+	errSnapshotGap      = errors.New("postage snapshot does not continue the synced blocks")
+	errSnapshotNoRange  = errors.New("postage snapshot delta has no block range")
+)
+
+// snapshotSegmentSize is the size of the uncompressed logs after which the
+// index of a streamed snapshot starts a new gzip member at the next block.
+const snapshotSegmentSize = 1 << 20
+
+// snapshotResumeMinBlocks is the number of blocks a snapshot has to reach
+// beyond the synced ones. The listener stops a tail of blocks before the
+// height of the snapshot, rounded down to its batch factor.
+const snapshotResumeMinBlocks = 16
+
+// SnapshotGetter returns the postage snapshot, the gzip compressed contract
+// events as newline delimited JSON sorted by block number.
 type SnapshotGetter interface {
-	GetBatchSnapshot() []byte
+	GetBatchSnapshot(ctx context.Context) ([]byte, error)
 }
 
+// ArchiveSnapshotBlockHeight is the last block of the snapshot compiled into
+// the batch-archive module. The module does not record the block it was
+// exported up to, so it is the block of its last event. A delta continuing
+// the compiled in snapshot starts with the next block, see beelite-snapshot
+// -after archive.
+const ArchiveSnapshotBlockHeight = 40391043
+
+// archiveSnapshotGetter returns the snapshot compiled into the batch-archive
+// module, it is as old as the module version.
 type archiveSnapshotGetter struct{}
 
-func (a archiveSnapshotGetter) GetBatchSnapshot() []byte {
-	return archive.GetBatchSnapshot()
+func (a archiveSnapshotGetter) GetBatchSnapshot(context.Context) ([]byte, error) {
+	return archive.GetBatchSnapshot(), nil
+}
+
+// GetSnapshotManifest returns the block range of the compiled in snapshot,
+// the snapshot is trusted without a signature.
+func (a archiveSnapshotGetter) GetSnapshotManifest(context.Context) (*SnapshotManifest, error) {
+	sum := sha256.Sum256(archive.GetBatchSnapshot())
+	return &SnapshotManifest{
+		ToBlock:        ArchiveSnapshotBlockHeight,
+		MaxBlockHeight: ArchiveSnapshotBlockHeight,
+		Hash:           hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// NewFileSnapshotGetter returns a SnapshotGetter reading the snapshot from a
+// local file.
+func NewFileSnapshotGetter(path string) SnapshotGetter {
+	return fileSnapshotGetter(path)
+}
+
+type fileSnapshotGetter string
+
+func (path fileSnapshotGetter) GetBatchSnapshot(context.Context) ([]byte, error) {
+	data, err := os.ReadFile(string(path))
+	if err != nil {
+		return nil, fmt.Errorf("read postage snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// NewURLSnapshotGetter returns a SnapshotGetter downloading the snapshot with
+// client and checking that its SHA-256 checksum is the given hex string, if
+// not empty. The snapshot is checked against its manifest too.
+func NewURLSnapshotGetter(client *http.Client, url, checksum string) SnapshotGetter {
+	return &urlSnapshotGetter{client: client, url: url, checksum: checksum}
+}
+
+type urlSnapshotGetter struct {
+	client   *http.Client
+	url      string
+	checksum string
+}
+
+func (g *urlSnapshotGetter) GetBatchSnapshot(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download postage snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download postage snapshot: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("download postage snapshot: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	if g.checksum != "" && !strings.EqualFold(hex.EncodeToString(sum[:]), g.checksum) {
+		return nil, errSnapshotChecksum
+	}
+	return data, nil
+}
+
+// snapshotGetter returns the SnapshotGetter selected by the options, nil for
+// the compiled in snapshot or a feed.
+func (lo *LiteOptions) snapshotGetter(client *http.Client) SnapshotGetter {
+	switch {
+	case lo.PostageSnapshotGetter != nil:
+		return lo.PostageSnapshotGetter
+	case lo.PostageSnapshotFile != "":
+		return NewFileSnapshotGetter(lo.PostageSnapshotFile)
+	case lo.PostageSnapshotURL != "":
+		return NewURLSnapshotGetter(client, lo.PostageSnapshotURL, lo.PostageSnapshotChecksum)
+	default:
+		return nil
+	}
+}
+
+// snapshotDeltas returns the postage snapshot deltas of the options, the
+// files and URLs followed by the getters. The URLs are checked against their
+// manifests.
+func (lo *LiteOptions) snapshotDeltas(client *http.Client) []SnapshotGetter {
+	deltas := make([]SnapshotGetter, 0, len(lo.PostageSnapshotDeltas)+len(lo.PostageSnapshotDeltaGetters))
+	for _, delta := range lo.PostageSnapshotDeltas {
+		if isSnapshotURL(delta) {
+			deltas = append(deltas, NewURLSnapshotGetter(client, delta, ""))
+		} else {
+			deltas = append(deltas, NewFileSnapshotGetter(delta))
+		}
+	}
+	return append(deltas, lo.PostageSnapshotDeltaGetters...)
+}
+
+func isSnapshotURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// snapshotSource names the source of the SnapshotGetter selected by the
+// options.
+func (lo *LiteOptions) snapshotSource() string {
+	switch {
+	case lo.PostageSnapshotGetter != nil:
+		return PostageSourceCustom
+	case lo.PostageSnapshotFile != "":
+		return PostageSourceFile
+	case lo.PostageSnapshotURL != "":
+		return PostageSourceURL
+	default:
+		return ""
+	}
+}
+
+// feedSnapshotGetter returns a snapshot retrieved from the feed with its
+// manifest.
+type feedSnapshotGetter struct {
+	data     []byte
+	manifest *SnapshotManifest
+}
+
+func (g *feedSnapshotGetter) GetBatchSnapshot(context.Context) ([]byte, error) {
+	return g.data, nil
+}
+
+func (g *feedSnapshotGetter) GetSnapshotManifest(context.Context) (*SnapshotManifest, error) {
+	return g.manifest, nil
+}
+
+// chainSnapshotGetter returns the events of a chain snapshot retrieved from
+// the feed, verified before they were converted to a snapshot. Its manifest
+// holds the block range and the hash of the converted snapshot.
+type chainSnapshotGetter struct {
+	feedSnapshotGetter
 }
 
 type SnapshotLogFilterer struct {
@@ -40,83 +211,420 @@ type SnapshotLogFilterer struct {
 	loadedLogs     []types.Log
 	maxBlockHeight uint64
 	initOnce       sync.Once
+	loadErr        error
 	getter         SnapshotGetter
+	deltas         []SnapshotGetter
+	fromBlock      uint64
+
+	// streaming mode, the logs are in an index file instead of loadedLogs
+	stream    bool
+	dir       string
+	indexFile *os.File
+	indexSize int64
+	segments  []snapshotSegment
+
+	trustedSigners []common.Address
+	metrics        snapshotMetrics
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// snapshotSegment is the offset of a gzip member in the index file and the
+// block of its first log. A member holds the logs of whole blocks.
+type snapshotSegment struct {
+	firstBlock uint64
+	offset     int64
+}
+
+// snapshotOpener is implemented by SnapshotGetters which can stream the
+// snapshot, so that it is not read into memory at once.
+type snapshotOpener interface {
+	openBatchSnapshot(ctx context.Context) (io.ReadCloser, error)
+}
+
+func (path fileSnapshotGetter) openBatchSnapshot(context.Context) (io.ReadCloser, error) {
+	file, err := os.Open(string(path))
+	if err != nil {
+		return nil, fmt.Errorf("open postage snapshot: %w", err)
+	}
+	return file, nil
 }
 
 func NewSnapshotLogFilterer(logger log.Logger, getter SnapshotGetter) *SnapshotLogFilterer {
 	return &SnapshotLogFilterer{
-		logger: logger,
-		getter: getter,
+		logger:  logger,
+		getter:  getter,
+		metrics: newSnapshotMetrics(),
+	}
+}
+
+// SetTrustedSigners makes the filterer accept only snapshots with a manifest
+// signed by one of the signers, it has to be called before the first query.
+// Snapshots from files, URLs and feeds are rejected without signers. The
+// snapshot compiled into bee-lite is always trusted.
+func (f *SnapshotLogFilterer) SetTrustedSigners(signers []common.Address) {
+	f.trustedSigners = signers
+}
+
+// NewStreamingSnapshotLogFilterer returns a SnapshotLogFilterer which does
+// not keep the logs in memory. It writes them to an index file in dir, the
+// temporary directory if empty, and reads the requested block ranges from
+// it. The file is removed by Close.
+func NewStreamingSnapshotLogFilterer(logger log.Logger, getter SnapshotGetter, dir string) *SnapshotLogFilterer {
+	return &SnapshotLogFilterer{
+		logger:  logger,
+		getter:  getter,
+		stream:  true,
+		dir:     dir,
+		metrics: newSnapshotMetrics(),
 	}
 }
 
+// AddSnapshotDeltas merges the deltas, snapshots of the blocks after the
+// previous one, into the snapshot in the given order. Every delta needs a
+// manifest with its block range. The snapshot given to
+// the constructor may be nil to merge only the deltas, e.g. to resync the
+// batches of a node after it was offline. It has to be called before the
+// first query.
+func (f *SnapshotLogFilterer) AddSnapshotDeltas(deltas ...SnapshotGetter) {
+	f.deltas = append(f.deltas, deltas...)
+}
+
 // loadSnapshot is responsible for loading and processing the snapshot data.
 // It is intended to be called exactly once by initOnce.Do.
-func (f *SnapshotLogFilterer) loadSnapshot() error {
-	f.logger.Info("loading batch snapshot")
-	data := f.getter.GetBatchSnapshot()
-	dataReader := bytes.NewReader(data)
-	gzipReader, err := gzip.NewReader(dataReader)
+func (f *SnapshotLogFilterer) loadSnapshot(ctx context.Context) (err error) {
+	f.logger.Info("loading batch snapshot", "streaming", f.stream, "deltas", len(f.deltas))
+
+	f.mu.RLock()
+	getter, deltas, closed := f.getter, f.deltas, f.closed
+	f.mu.RUnlock()
+	if closed {
+		return errSnapshotClosed
+	}
+	if getter == nil && len(deltas) == 0 {
+		return errors.New("no postage snapshot")
+	}
+
+	logs := &snapshotLogs{}
+	if f.stream {
+		if err := logs.createIndex(f.dir); err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				logs.removeIndex()
+			}
+		}()
+	}
+
+	if getter != nil {
+		if err := f.loadPart(ctx, getter, true, logs); err != nil {
+			return err
+		}
+	}
+	for i, delta := range deltas {
+		if err := f.loadPart(ctx, delta, false, logs); err != nil {
+			return fmt.Errorf("postage snapshot delta %d: %w", i, err)
+		}
+	}
+	if err := logs.flush(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return errSnapshotClosed
+	}
+	f.loadedLogs = logs.logs
+	f.indexFile = logs.file
+	f.indexSize = logs.offset
+	f.segments = slices.Clip(logs.segments)
+	f.fromBlock = logs.fromBlock
+	f.maxBlockHeight = logs.toBlock
+
+	f.logger.Info("batch snapshot loaded successfully", "log_count", logs.count, "from_block", f.fromBlock, "max_block_height", f.maxBlockHeight)
+	return nil
+}
+
+// loadPart verifies the snapshot, or a delta, and adds its logs of the blocks
+// after the ones covered by the parts before it. The content is read once and
+// hashed while it is parsed, the logs are only used by loadSnapshot if the
+// digest matches the manifest.
+func (f *SnapshotLogFilterer) loadPart(ctx context.Context, getter SnapshotGetter, base bool, logs *snapshotLogs) error {
+	manifest, err := f.verifyManifest(ctx, getter)
+	if err != nil {
+		f.logger.Error(err, "failed to verify batch snapshot")
+		return err
+	}
+
+	var reader io.Reader
+	if opener, ok := getter.(snapshotOpener); ok && f.stream {
+		rc, err := opener.openBatchSnapshot(ctx)
+		if err != nil {
+			f.logger.Error(err, "failed to open batch snapshot")
+			return err
+		}
+		defer rc.Close()
+		reader = rc
+	} else {
+		data, err := getter.GetBatchSnapshot(ctx)
+		if err != nil {
+			f.logger.Error(err, "failed to get batch snapshot")
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	sum := sha256.New()
+	reader = io.TeeReader(reader, sum)
+
+	// verified hashes the rest of the content and reports a digest not
+	// matching the manifest instead of err, which may be caused by it
+	verified := func(err error) error {
+		if manifest == nil {
+			return err
+		}
+		if _, errCopy := io.Copy(io.Discard, reader); errCopy != nil {
+			return errors.Join(err, fmt.Errorf("hash postage snapshot: %w", errCopy))
+		}
+		if errHash := manifest.checkHash(sum.Sum(nil)); errHash != nil {
+			f.logger.Error(errHash, "failed to verify batch snapshot")
+			return errHash
+		}
+		return err
+	}
+
+	gzipReader, err := gzip.NewReader(reader)
 	if err != nil {
 		f.logger.Error(err, "failed to create gzip reader for batch import")
-		return fmt.Errorf("create gzip reader: %w", err)
+		return verified(fmt.Errorf("create gzip reader: %w", err))
 	}
 	defer gzipReader.Close()
 
-	if err := f.parseLogs(gzipReader); err != nil {
+	// The block range of the part is only known from its manifest, a delta
+	// needs one to detect gaps, a base snapshot without one is assumed to
+	// start with the postage contract.
+	rangeKnown := manifest != nil && manifest.ToBlock != 0
+	if !base && (!rangeKnown || manifest.FromBlock > manifest.ToBlock) {
+		return errSnapshotNoRange
+	}
+	switch {
+	case rangeKnown && !logs.started:
+		logs.start(manifest.FromBlock)
+	case rangeKnown:
+		if manifest.FromBlock > logs.toBlock+1 {
+			return fmt.Errorf("%w: starts at block %d after block %d", errSnapshotGap, manifest.FromBlock, logs.toBlock+1)
+		}
+	default:
+		logs.start(0)
+	}
+
+	after, skip := logs.toBlock, logs.started
+	maxBlockHeight, err := decodeLogs(gzipReader, func(raw json.RawMessage, logEntry types.Log) error {
+		// the blocks of the previous parts are complete
+		if skip && logEntry.BlockNumber <= after {
+			return nil
+		}
+		if !logs.started {
+			logs.start(logEntry.BlockNumber)
+		}
+		return logs.add(raw, logEntry)
+	})
+	if err != nil {
 		f.logger.Error(err, "failed to parse logs from snapshot")
+		return verified(err)
+	}
+	if err := verified(nil); err != nil {
 		return err
 	}
+	if manifest != nil {
+		f.logger.Info("postage snapshot verified", "hash", manifest.Hash, "signer", manifest.Signer)
+	}
+
+	logs.toBlock = max(logs.toBlock, maxBlockHeight)
+	if rangeKnown {
+		logs.toBlock = max(logs.toBlock, manifest.ToBlock)
+	}
+	return nil
+}
+
+// snapshotLogs collects the logs of the snapshot and its deltas, in memory or
+// in the index file, and the range of blocks they cover.
+type snapshotLogs struct {
+	logs []types.Log
 
-	f.logger.Info("batch snapshot loaded successfully", "log_count", len(f.loadedLogs), "max_block_height", f.maxBlockHeight)
+	// streaming mode, the logs are written to the index file, one JSON
+	// object per line, compressed in gzip members of whole blocks whose
+	// offsets are recorded
+	file        *os.File
+	w           *bufio.Writer
+	gw          *gzip.Writer
+	offset      int64
+	segmentSize int
+	lastBlock   uint64
+	segments    []snapshotSegment
+
+	count     int
+	started   bool
+	fromBlock uint64
+	toBlock   uint64
+}
+
+func (s *snapshotLogs) createIndex(dir string) error {
+	file, err := os.CreateTemp(dir, "postage-snapshot-*.ndjson.gz")
+	if err != nil {
+		return fmt.Errorf("create snapshot index: %w", err)
+	}
+	s.file = file
+	s.w = bufio.NewWriter(file)
 	return nil
 }
 
-func (f *SnapshotLogFilterer) parseLogs(reader io.Reader) error {
-	var parsedLogs []types.Log
-	var currentMaxBlockHeight uint64
+func (s *snapshotLogs) removeIndex() {
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
+
+func (s *snapshotLogs) start(fromBlock uint64) {
+	s.started = true
+	s.fromBlock = fromBlock
+}
+
+func (s *snapshotLogs) add(raw json.RawMessage, logEntry types.Log) error {
+	s.count++
+	if s.w == nil {
+		s.logs = append(s.logs, logEntry)
+		return nil
+	}
+
+	// a segment is only cut between blocks
+	if s.gw != nil && s.segmentSize >= snapshotSegmentSize && s.lastBlock != logEntry.BlockNumber {
+		if err := s.closeSegment(); err != nil {
+			return err
+		}
+	}
+	if s.gw == nil {
+		s.segments = append(s.segments, snapshotSegment{firstBlock: logEntry.BlockNumber, offset: s.offset})
+		s.gw = gzip.NewWriter(countWriter{w: s.w, n: &s.offset})
+		s.segmentSize = 0
+	}
+	s.lastBlock = logEntry.BlockNumber
+	n, err := s.gw.Write(append(raw, '\n'))
+	s.segmentSize += n
+	if err != nil {
+		return fmt.Errorf("write snapshot index: %w", err)
+	}
+	return nil
+}
+
+// closeSegment ends the gzip member of the current segment.
+func (s *snapshotLogs) closeSegment() error {
+	err := s.gw.Close()
+	s.gw = nil
+	if err != nil {
+		return fmt.Errorf("write snapshot index: %w", err)
+	}
+	return nil
+}
+
+func (s *snapshotLogs) flush() error {
+	if s.w == nil {
+		return nil
+	}
+	if s.gw != nil {
+		if err := s.closeSegment(); err != nil {
+			return err
+		}
+	}
+	if err := s.w.Flush(); err != nil {
+		return fmt.Errorf("write snapshot index: %w", err)
+	}
+	return nil
+}
+
+// countWriter counts the bytes written to w.
+type countWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+// decodeLogs calls fn with every log of the snapshot and returns the highest
+// block number. The logs have to be sorted by block number.
+func decodeLogs(reader io.Reader, fn func(raw json.RawMessage, logEntry types.Log) error) (uint64, error) {
+	var (
+		currentMaxBlockHeight uint64
+		count                 int
+	)
 
 	decoder := json.NewDecoder(reader)
-	for {
-		var logEntry types.Log
-		if err := decoder.Decode(&logEntry); err != nil {
+	for ; ; count++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
 			if err == io.EOF {
 				break
 			}
-			return fmt.Errorf("%w: failed to decode log event at position %d: %w", listener.ErrParseSnapshot, len(parsedLogs), err)
+			return 0, fmt.Errorf("%w: failed to decode log event at position %d: %w", listener.ErrParseSnapshot, count, err)
+		}
+		var logEntry types.Log
+		if err := json.Unmarshal(raw, &logEntry); err != nil {
+			return 0, fmt.Errorf("%w: failed to decode log event at position %d: %w", listener.ErrParseSnapshot, count, err)
 		}
 
 		// Validate sorting order (required for binary search in FilterLogs)
-		if logEntry.BlockNumber < currentMaxBlockHeight {
-			return fmt.Errorf("%w: snapshot data is not sorted by block number at index %d (block %d < %d)",
-				listener.ErrParseSnapshot, len(parsedLogs), logEntry.BlockNumber, currentMaxBlockHeight)
+		if err := checkLogOrder(count, logEntry.BlockNumber, currentMaxBlockHeight); err != nil {
+			return 0, err
 		}
 
 		if logEntry.BlockNumber > currentMaxBlockHeight {
 			currentMaxBlockHeight = logEntry.BlockNumber
 		}
-		parsedLogs = append(parsedLogs, logEntry)
+		if err := fn(raw, logEntry); err != nil {
+			return 0, err
+		}
 	}
 
-	f.loadedLogs = parsedLogs
-	f.maxBlockHeight = currentMaxBlockHeight
+	return currentMaxBlockHeight, nil
+}
+
+// checkLogOrder checks that the log at index is not in a block before the
+// logs preceding it.
+func checkLogOrder(index int, blockNumber, maxBlockHeight uint64) error {
+	if blockNumber < maxBlockHeight {
+		return fmt.Errorf("%w: snapshot data is not sorted by block number at index %d (block %d < %d)",
+			listener.ErrParseSnapshot, index, blockNumber, maxBlockHeight)
+	}
 	return nil
 }
 
 // ensureLoaded calls loadSnapshot via sync.Once to ensure thread-safe, one-time initialization.
-func (f *SnapshotLogFilterer) ensureLoaded() error {
-	var err error
+func (f *SnapshotLogFilterer) ensureLoaded(ctx context.Context) error {
 	f.initOnce.Do(func() {
-		err = f.loadSnapshot()
+		f.loadErr = f.loadSnapshot(ctx)
 	})
-	return err
+	return f.loadErr
 }
 
 func (f *SnapshotLogFilterer) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
-	if err := f.ensureLoaded(); err != nil {
+	if err := f.ensureLoaded(ctx); err != nil {
 		return nil, fmt.Errorf("failed to ensure snapshot was loaded for FilterLogs: %w", err)
 	}
 
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return nil, errSnapshotClosed
+	}
+	if f.stream {
+		return f.filterIndexedLogs(query)
+	}
+
 	f.logger.Debug("filtering pre-loaded logs", "total_logs", len(f.loadedLogs), "query_from_block", query.FromBlock, "query_to_block", query.ToBlock, "query_addresses_count", len(query.Addresses), "query_topics_count", len(query.Topics))
 
 	filtered := make([]types.Log, 0)
@@ -138,41 +646,133 @@ func (f *SnapshotLogFilterer) FilterLogs(ctx context.Context, query ethereum.Fil
 			break
 		}
 
-		if len(query.Addresses) > 0 && !slices.Contains(query.Addresses, logEntry.Address) {
-			continue
+		if matchLog(query, logEntry) {
+			filtered = append(filtered, logEntry)
 		}
+	}
 
-		if len(query.Topics) > 0 {
-			match := true
-			for topicIndex, topicCriteria := range query.Topics {
-				if len(topicCriteria) == 0 {
-					continue
-				}
-				if topicIndex >= len(logEntry.Topics) {
-					match = false
-					break
-				}
-
-				if !slices.Contains(topicCriteria, logEntry.Topics[topicIndex]) {
-					match = false
-					break
-				}
-			}
-			if !match {
-				continue
+	f.logger.Debug("filtered logs complete", "input_log_count", len(f.loadedLogs), "potential_logs_in_block_range", scannedCount, "output_count", len(filtered))
+	return filtered, nil
+}
+
+// filterIndexedLogs reads the logs of the queried block range from the index
+// file, starting with the segment holding the first queried block.
+func (f *SnapshotLogFilterer) filterIndexedLogs(query ethereum.FilterQuery) ([]types.Log, error) {
+	f.logger.Debug("filtering indexed logs", "total_segments", len(f.segments), "query_from_block", query.FromBlock, "query_to_block", query.ToBlock, "query_addresses_count", len(query.Addresses), "query_topics_count", len(query.Topics))
+
+	filtered := make([]types.Log, 0)
+	if len(f.segments) == 0 {
+		return filtered, nil
+	}
+
+	var fromBlockNum uint64
+	if query.FromBlock != nil {
+		fromBlockNum = query.FromBlock.Uint64()
+	}
+	start := sort.Search(len(f.segments), func(i int) bool {
+		return f.segments[i].firstBlock > fromBlockNum
+	})
+	start = max(start-1, 0)
+
+	// the gzip reader continues with the following members
+	offset := f.segments[start].offset
+	gzipReader, err := gzip.NewReader(bufio.NewReader(io.NewSectionReader(f.indexFile, offset, f.indexSize-offset)))
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot index: %w", err)
+	}
+	defer gzipReader.Close()
+	decoder := json.NewDecoder(gzipReader)
+
+	scannedCount := 0
+	for {
+		var logEntry types.Log
+		if err := decoder.Decode(&logEntry); err != nil {
+			if err == io.EOF {
+				break
 			}
+			return nil, fmt.Errorf("read snapshot index: %w", err)
+		}
+		if logEntry.BlockNumber < fromBlockNum {
+			continue
+		}
+		scannedCount++
+
+		if query.ToBlock != nil && logEntry.BlockNumber > query.ToBlock.Uint64() {
+			break
 		}
 
-		filtered = append(filtered, logEntry)
+		if matchLog(query, logEntry) {
+			filtered = append(filtered, logEntry)
+		}
 	}
 
-	f.logger.Debug("filtered logs complete", "input_log_count", len(f.loadedLogs), "potential_logs_in_block_range", scannedCount, "output_count", len(filtered))
+	f.logger.Debug("filtered logs complete", "potential_logs_in_block_range", scannedCount, "output_count", len(filtered))
 	return filtered, nil
 }
 
-func (f *SnapshotLogFilterer) BlockNumber(_ context.Context) (uint64, error) {
-	if err := f.ensureLoaded(); err != nil {
+// matchLog reports whether the log matches the addresses and topics of the
+// query.
+func matchLog(query ethereum.FilterQuery, logEntry types.Log) bool {
+	if len(query.Addresses) > 0 && !slices.Contains(query.Addresses, logEntry.Address) {
+		return false
+	}
+
+	for topicIndex, topicCriteria := range query.Topics {
+		if len(topicCriteria) == 0 {
+			continue
+		}
+		if topicIndex >= len(logEntry.Topics) {
+			return false
+		}
+		if !slices.Contains(topicCriteria, logEntry.Topics[topicIndex]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *SnapshotLogFilterer) BlockNumber(ctx context.Context) (uint64, error) {
+	if err := f.ensureLoaded(ctx); err != nil {
 		return 0, fmt.Errorf("failed to ensure snapshot was loaded for BlockNumber: %w", err)
 	}
 	return f.maxBlockHeight, nil
 }
+
+// checkResume checks that the snapshot covers the blocks after block, the last
+// one the batches are synced to, and reaches far enough beyond it.
+func (f *SnapshotLogFilterer) checkResume(ctx context.Context, block uint64) error {
+	if err := f.ensureLoaded(ctx); err != nil {
+		return err
+	}
+	if f.fromBlock > block+1 {
+		return fmt.Errorf("%w: starts at block %d after block %d", errSnapshotGap, f.fromBlock, block+1)
+	}
+	if f.maxBlockHeight < block+snapshotResumeMinBlocks {
+		return fmt.Errorf("%w: ends at block %d, synced to block %d", errSnapshotGap, f.maxBlockHeight, block)
+	}
+	return nil
+}
+
+// Close frees the logs of the snapshot and removes the index file, it is
+// called once the postage batches are synced from the snapshot. FilterLogs
+// fails afterwards, BlockNumber still returns the height of the snapshot.
+func (f *SnapshotLogFilterer) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	f.loadedLogs = nil
+	f.segments = nil
+	f.getter = nil
+	f.deltas = nil
+
+	if f.indexFile == nil {
+		return nil
+	}
+	name := f.indexFile.Name()
+	err := f.indexFile.Close()
+	f.indexFile = nil
+	return errors.Join(err, os.Remove(name))
+}
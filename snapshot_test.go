@@ -0,0 +1,187 @@
+package beelite
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/log"
+)
+
+func TestSnapshotDeltas(t *testing.T) {
+	t.Parallel()
+
+	key, signer := testSignerKey(t)
+
+	base := func(t *testing.T) SnapshotGetter {
+		data, manifest := testSnapshot(t, 10, 100, nil)
+		return manifestSnapshotGetter{data: data, manifest: manifest}
+	}
+	delta := func(t *testing.T, fromBlock, toBlock uint64) SnapshotGetter {
+		data, manifest := testSnapshot(t, fromBlock, toBlock, nil)
+		return manifestSnapshotGetter{data: data, manifest: manifest}
+	}
+	noRange := func(t *testing.T) SnapshotGetter {
+		data, manifest := testSnapshot(t, 101, 200, nil)
+		manifest.FromBlock, manifest.ToBlock = 0, 0
+		return manifestSnapshotGetter{data: data, manifest: manifest}
+	}
+	signedFile := func(t *testing.T, fromBlock, toBlock uint64) SnapshotGetter {
+		data, manifest := testSnapshot(t, fromBlock, toBlock, key)
+		return NewFileSnapshotGetter(writeTestSnapshot(t, t.TempDir(), "delta.gz", data, manifest))
+	}
+
+	for _, tc := range []struct {
+		name          string
+		base          func(t *testing.T) SnapshotGetter
+		deltas        func(t *testing.T) []SnapshotGetter
+		signers       []common.Address
+		wantErr       error
+		wantFromBlock uint64
+		wantHeight    uint64
+		wantLogs      int
+	}{
+		{
+			name: "continuing delta",
+			base: base,
+			deltas: func(t *testing.T) []SnapshotGetter {
+				return []SnapshotGetter{delta(t, 101, 200)}
+			},
+			wantFromBlock: 10,
+			wantHeight:    200,
+			wantLogs:      191,
+		},
+		{
+			name: "overlapping deltas",
+			base: base,
+			deltas: func(t *testing.T) []SnapshotGetter {
+				return []SnapshotGetter{delta(t, 50, 150), delta(t, 120, 200)}
+			},
+			wantFromBlock: 10,
+			wantHeight:    200,
+			wantLogs:      191,
+		},
+		{
+			name: "signed delta file",
+			base: func(t *testing.T) SnapshotGetter {
+				return signedFile(t, 10, 100)
+			},
+			deltas: func(t *testing.T) []SnapshotGetter {
+				return []SnapshotGetter{signedFile(t, 101, 200)}
+			},
+			signers:       []common.Address{signer},
+			wantFromBlock: 10,
+			wantHeight:    200,
+			wantLogs:      191,
+		},
+		{
+			name: "deltas only",
+			deltas: func(t *testing.T) []SnapshotGetter {
+				return []SnapshotGetter{delta(t, 101, 200), delta(t, 201, 300)}
+			},
+			wantFromBlock: 101,
+			wantHeight:    300,
+			wantLogs:      200,
+		},
+		{
+			name: "delta after a gap",
+			base: base,
+			deltas: func(t *testing.T) []SnapshotGetter {
+				return []SnapshotGetter{delta(t, 150, 200)}
+			},
+			wantErr: errSnapshotGap,
+		},
+		{
+			name: "delta without block range",
+			base: base,
+			deltas: func(t *testing.T) []SnapshotGetter {
+				return []SnapshotGetter{noRange(t)}
+			},
+			wantErr: errSnapshotNoRange,
+		},
+		{
+			name: "delta without manifest",
+			base: base,
+			deltas: func(t *testing.T) []SnapshotGetter {
+				data, _ := testSnapshot(t, 101, 200, nil)
+				return []SnapshotGetter{manifestSnapshotGetter{data: data}}
+			},
+			wantErr: ErrSnapshotUntrusted,
+		},
+	} {
+		for _, stream := range []bool{false, true} {
+			name := tc.name
+			if stream {
+				name += " streamed"
+			}
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				var getter SnapshotGetter
+				if tc.base != nil {
+					getter = tc.base(t)
+				}
+				f := NewSnapshotLogFilterer(log.Noop, getter)
+				if stream {
+					f = NewStreamingSnapshotLogFilterer(log.Noop, getter, t.TempDir())
+				}
+				f.SetTrustedSigners(tc.signers)
+				f.AddSnapshotDeltas(tc.deltas(t)...)
+				defer f.Close()
+
+				logs, err := f.FilterLogs(context.Background(), ethereum.FilterQuery{})
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tc.wantErr)
+				}
+				if tc.wantErr != nil {
+					return
+				}
+				if len(logs) != tc.wantLogs {
+					t.Fatalf("got %d logs, want %d", len(logs), tc.wantLogs)
+				}
+				for i, l := range logs {
+					if want := tc.wantFromBlock + uint64(i); l.BlockNumber != want {
+						t.Fatalf("got log %d in block %d, want %d", i, l.BlockNumber, want)
+					}
+				}
+				height, err := f.BlockNumber(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if height != tc.wantHeight {
+					t.Fatalf("got block height %d, want %d", height, tc.wantHeight)
+				}
+			})
+		}
+	}
+}
+
+func TestSnapshotFilterLogsRange(t *testing.T) {
+	t.Parallel()
+
+	data, manifest := testSnapshot(t, 1, 5000, nil)
+	getter := manifestSnapshotGetter{data: data, manifest: manifest}
+
+	for _, stream := range []bool{false, true} {
+		f := NewSnapshotLogFilterer(log.Noop, getter)
+		if stream {
+			f = NewStreamingSnapshotLogFilterer(log.Noop, getter, t.TempDir())
+		}
+		logs, err := f.FilterLogs(context.Background(), ethereum.FilterQuery{
+			FromBlock: big.NewInt(2500),
+			ToBlock:   big.NewInt(2599),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(logs) != 100 || logs[0].BlockNumber != 2500 || logs[99].BlockNumber != 2599 {
+			t.Fatalf("streamed %t: got %d logs of blocks %d to %d, want 100 of blocks 2500 to 2599", stream, len(logs), logs[0].BlockNumber, logs[len(logs)-1].BlockNumber)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package beelite
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	m "github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// snapshotManifestSuffix is appended to the path or URL of a snapshot for the
+// location of its manifest, the same way as beelite-snapshot names it.
+const snapshotManifestSuffix = ".json"
+
+// ErrSnapshotUntrusted is returned when the postage snapshot is not signed by
+// one of the trusted signers or its content does not match the signed hash.
+var ErrSnapshotUntrusted = errors.New("postage snapshot is not trusted")
+
+// SnapshotManifestGetter is implemented by SnapshotGetters which provide the
+// manifest of the snapshot, with its hash and signature. It returns nil if
+// the snapshot has no manifest.
+type SnapshotManifestGetter interface {
+	GetSnapshotManifest(ctx context.Context) (*SnapshotManifest, error)
+}
+
+// trustedSnapshotGetter is implemented by the getters of the snapshot
+// compiled into bee-lite and of a verified chain snapshot, which need no
+// signature.
+type trustedSnapshotGetter interface {
+	trustedSnapshot()
+}
+
+func (archiveSnapshotGetter) trustedSnapshot() {}
+func (*chainSnapshotGetter) trustedSnapshot()  {}
+
+func (path fileSnapshotGetter) GetSnapshotManifest(context.Context) (*SnapshotManifest, error) {
+	data, err := os.ReadFile(string(path) + snapshotManifestSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot manifest: %w", err)
+	}
+	return parseSnapshotManifest(data)
+}
+
+func (g *urlSnapshotGetter) GetSnapshotManifest(ctx context.Context) (*SnapshotManifest, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.url+snapshotManifestSuffix, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download snapshot manifest: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("download snapshot manifest: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("download snapshot manifest: %w", err)
+	}
+	return parseSnapshotManifest(data)
+}
+
+func parseSnapshotManifest(data []byte) (*SnapshotManifest, error) {
+	manifest := &SnapshotManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// Verify checks that the manifest is signed by one of the trusted signers.
+func (m *SnapshotManifest) Verify(trusted []common.Address) error {
+	digest, err := hex.DecodeString(m.Hash)
+	if err != nil || len(digest) != sha256.Size {
+		return fmt.Errorf("%w: invalid hash %q", ErrSnapshotUntrusted, m.Hash)
+	}
+	sig, err := hex.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature", ErrSnapshotUntrusted)
+	}
+	pubKey, err := crypto.Recover(sig, digest)
+	if err != nil {
+		return fmt.Errorf("%w: recover signer: %w", ErrSnapshotUntrusted, err)
+	}
+	signer, err := crypto.NewEthereumAddress(*pubKey)
+	if err != nil {
+		return err
+	}
+	addr := common.BytesToAddress(signer)
+
+	if m.Signer != "" && !strings.EqualFold(m.Signer, addr.Hex()) {
+		return fmt.Errorf("%w: signed by %s instead of %s", ErrSnapshotUntrusted, addr, m.Signer)
+	}
+	if !slices.Contains(trusted, addr) {
+		return fmt.Errorf("%w: signer %s", ErrSnapshotUntrusted, addr)
+	}
+	return nil
+}
+
+// signedSnapshotGetter is implemented by the SnapshotGetters of the file, URL
+// and feed sources, whose snapshots are only used with a manifest signed by
+// one of the trusted signers.
+type signedSnapshotGetter interface {
+	requiresSigners()
+}
+
+func (fileSnapshotGetter) requiresSigners()  {}
+func (*urlSnapshotGetter) requiresSigners()  {}
+func (*feedSnapshotGetter) requiresSigners() {}
+
+// verifyManifest returns the manifest of the snapshot, checked against the
+// trusted signers. Every snapshot other than the compiled in one needs a
+// manifest, those of the file, URL and feed sources a signed one.
+func (f *SnapshotLogFilterer) verifyManifest(ctx context.Context, getter SnapshotGetter) (*SnapshotManifest, error) {
+	if _, ok := getter.(trustedSnapshotGetter); ok {
+		// a verified chain snapshot still has the block range of its manifest
+		if mg, ok := getter.(SnapshotManifestGetter); ok {
+			return mg.GetSnapshotManifest(ctx)
+		}
+		return nil, nil
+	}
+
+	var manifest *SnapshotManifest
+	if mg, ok := getter.(SnapshotManifestGetter); ok {
+		var err error
+		if manifest, err = mg.GetSnapshotManifest(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%w: no snapshot manifest", ErrSnapshotUntrusted)
+	}
+	if _, ok := getter.(signedSnapshotGetter); ok && len(f.trustedSigners) == 0 {
+		return nil, fmt.Errorf("%w: no trusted signers", ErrSnapshotUntrusted)
+	}
+
+	if len(f.trustedSigners) > 0 {
+		start := time.Now()
+		defer func() { f.metrics.VerificationDuration.Observe(time.Since(start).Seconds()) }()
+		if err := manifest.Verify(f.trustedSigners); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+// checkHash checks the SHA-256 digest of the content of the snapshot against
+// the hash of its manifest.
+func (m *SnapshotManifest) checkHash(digest []byte) error {
+	if !strings.EqualFold(hex.EncodeToString(digest), m.Hash) {
+		return fmt.Errorf("%w: %w", ErrSnapshotUntrusted, errSnapshotChecksum)
+	}
+	return nil
+}
+
+type snapshotMetrics struct {
+	VerificationDuration prometheus.Histogram
+}
+
+func newSnapshotMetrics() snapshotMetrics {
+	subsystem := "beelite_postage_snapshot"
+
+	return snapshotMetrics{
+		VerificationDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "verification_duration_seconds",
+			Help:      "Time spent verifying the signature of the postage snapshot manifest.",
+		}),
+	}
+}
+
+func (f *SnapshotLogFilterer) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(f.metrics)
+}
@@ -0,0 +1,356 @@
+package beelite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/postage"
+)
+
+// manifestSnapshotGetter is a custom SnapshotGetter with a manifest.
+type manifestSnapshotGetter struct {
+	data     []byte
+	manifest *SnapshotManifest
+}
+
+func (g manifestSnapshotGetter) GetBatchSnapshot(context.Context) ([]byte, error) {
+	return g.data, nil
+}
+
+func (g manifestSnapshotGetter) GetSnapshotManifest(context.Context) (*SnapshotManifest, error) {
+	return g.manifest, nil
+}
+
+// testSnapshot returns a snapshot with a log in every block from fromBlock to
+// toBlock and its manifest, signed by key if not nil.
+func testSnapshot(t *testing.T, fromBlock, toBlock uint64, key *ecdsa.PrivateKey) ([]byte, *SnapshotManifest) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gzipWriter)
+	for block := fromBlock; block <= toBlock; block++ {
+		err := encoder.Encode(types.Log{
+			Address:     common.HexToAddress("0x45a1502382541cd610cc9068e88727426b696293"),
+			Topics:      []common.Hash{{1}},
+			BlockNumber: block,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	manifest := &SnapshotManifest{
+		FromBlock:      fromBlock,
+		ToBlock:        toBlock,
+		MaxBlockHeight: toBlock,
+		LogCount:       int(toBlock - fromBlock + 1),
+		Hash:           hex.EncodeToString(sum[:]),
+	}
+	if key != nil {
+		if err := manifest.Sign(crypto.NewDefaultSigner(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes(), manifest
+}
+
+// writeTestSnapshot writes the snapshot and, if not nil, its manifest to dir
+// and returns the path of the snapshot.
+func writeTestSnapshot(t *testing.T, dir, name string, data []byte, manifest *SnapshotManifest) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if manifest != nil {
+		m, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path+snapshotManifestSuffix, m, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func testSignerKey(t *testing.T) (*ecdsa.PrivateKey, common.Address) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, common.BytesToAddress(address)
+}
+
+func TestVerifySnapshot(t *testing.T) {
+	t.Parallel()
+
+	key, signer := testSignerKey(t)
+	otherKey, _ := testSignerKey(t)
+
+	data, signed := testSnapshot(t, 10, 100, key)
+	_, unsigned := testSnapshot(t, 10, 100, nil)
+	_, otherSigned := testSnapshot(t, 10, 100, otherKey)
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-1] ^= 0xff
+
+	dir := t.TempDir()
+	signedFile := writeTestSnapshot(t, dir, "signed.gz", data, signed)
+	noManifestFile := writeTestSnapshot(t, dir, "no-manifest.gz", data, nil)
+	otherSignedFile := writeTestSnapshot(t, dir, "other-signed.gz", data, otherSigned)
+	tamperedFile := writeTestSnapshot(t, dir, "tampered.gz", tampered, signed)
+
+	for _, tc := range []struct {
+		name    string
+		getter  SnapshotGetter
+		signers []common.Address
+		wantErr error
+	}{
+		{
+			name:    "file signed by trusted signer",
+			getter:  NewFileSnapshotGetter(signedFile),
+			signers: []common.Address{signer},
+		},
+		{
+			name:    "file without signers",
+			getter:  NewFileSnapshotGetter(signedFile),
+			wantErr: ErrSnapshotUntrusted,
+		},
+		{
+			name:    "file without manifest",
+			getter:  NewFileSnapshotGetter(noManifestFile),
+			signers: []common.Address{signer},
+			wantErr: ErrSnapshotUntrusted,
+		},
+		{
+			name:    "file signed by other signer",
+			getter:  NewFileSnapshotGetter(otherSignedFile),
+			signers: []common.Address{signer},
+			wantErr: ErrSnapshotUntrusted,
+		},
+		{
+			name:    "file content not matching hash",
+			getter:  NewFileSnapshotGetter(tamperedFile),
+			signers: []common.Address{signer},
+			wantErr: ErrSnapshotUntrusted,
+		},
+		{
+			name:    "feed signed by trusted signer",
+			getter:  &feedSnapshotGetter{data: data, manifest: signed},
+			signers: []common.Address{signer},
+		},
+		{
+			name:    "feed without signers",
+			getter:  &feedSnapshotGetter{data: data, manifest: signed},
+			wantErr: ErrSnapshotUntrusted,
+		},
+		{
+			name:   "custom getter with unsigned manifest",
+			getter: manifestSnapshotGetter{data: data, manifest: unsigned},
+		},
+		{
+			name:    "custom getter with unsigned manifest and signers",
+			getter:  manifestSnapshotGetter{data: data, manifest: unsigned},
+			signers: []common.Address{signer},
+			wantErr: ErrSnapshotUntrusted,
+		},
+		{
+			name:    "custom getter without manifest",
+			getter:  manifestSnapshotGetter{data: data},
+			wantErr: ErrSnapshotUntrusted,
+		},
+		{
+			name:    "custom getter content not matching hash",
+			getter:  manifestSnapshotGetter{data: tampered, manifest: unsigned},
+			wantErr: ErrSnapshotUntrusted,
+		},
+	} {
+		for _, stream := range []bool{false, true} {
+			name := tc.name
+			if stream {
+				name += " streamed"
+			}
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				f := NewSnapshotLogFilterer(log.Noop, tc.getter)
+				if stream {
+					f = NewStreamingSnapshotLogFilterer(log.Noop, tc.getter, t.TempDir())
+				}
+				f.SetTrustedSigners(tc.signers)
+				defer f.Close()
+
+				height, err := f.BlockNumber(context.Background())
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tc.wantErr)
+				}
+				if tc.wantErr == nil && height != 100 {
+					t.Fatalf("got block height %d, want 100", height)
+				}
+			})
+		}
+	}
+}
+
+func TestVerifySnapshotArchive(t *testing.T) {
+	t.Parallel()
+
+	data, deltaManifest := testSnapshot(t, ArchiveSnapshotBlockHeight+1, ArchiveSnapshotBlockHeight+10, nil)
+	f := NewSnapshotLogFilterer(log.Noop, archiveSnapshotGetter{})
+	f.AddSnapshotDeltas(manifestSnapshotGetter{data: data, manifest: deltaManifest})
+	defer f.Close()
+
+	manifest, err := f.verifyManifest(context.Background(), archiveSnapshotGetter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.ToBlock != ArchiveSnapshotBlockHeight {
+		t.Fatalf("got block height %d of the compiled in snapshot, want %d", manifest.ToBlock, ArchiveSnapshotBlockHeight)
+	}
+
+	// the compiled in snapshot ends with its block height and is continued by
+	// a delta starting after it
+	logs, err := f.FilterLogs(context.Background(), ethereum.FilterQuery{
+		FromBlock: big.NewInt(ArchiveSnapshotBlockHeight - 1000),
+		ToBlock:   big.NewInt(ArchiveSnapshotBlockHeight),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) == 0 || logs[len(logs)-1].BlockNumber != ArchiveSnapshotBlockHeight {
+		t.Fatalf("compiled in snapshot does not end with an event in block %d", ArchiveSnapshotBlockHeight)
+	}
+	height, err := f.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != ArchiveSnapshotBlockHeight+10 {
+		t.Fatalf("got block height %d, want %d", height, ArchiveSnapshotBlockHeight+10)
+	}
+}
+
+func TestParseFeedSnapshot(t *testing.T) {
+	t.Parallel()
+
+	key, signer := testSignerKey(t)
+	otherKey, _ := testSignerKey(t)
+
+	chainSnapshot := func(t *testing.T, key *ecdsa.PrivateKey) []byte {
+		t.Helper()
+
+		state, err := json.Marshal(postage.ChainSnapshot{
+			Events: []types.Log{
+				{Topics: []common.Hash{{1}}, BlockNumber: 20},
+				{Topics: []common.Hash{{1}}, BlockNumber: 30},
+			},
+			LastBlockNumber: 100,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(state)
+		manifest := &SnapshotManifest{Hash: hex.EncodeToString(sum[:])}
+		if err := manifest.Sign(crypto.NewDefaultSigner(key)); err != nil {
+			t.Fatal(err)
+		}
+		content, err := json.Marshal(FeedSnapshot{Manifest: manifest, ChainSnapshot: state})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+	data, manifest := testSnapshot(t, 10, 100, key)
+	snapshot, err := json.Marshal(FeedSnapshot{Manifest: manifest, Snapshot: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	noManifest, err := json.Marshal(FeedSnapshot{Snapshot: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		content    []byte
+		signers    []common.Address
+		wantErr    error
+		wantHeight uint64
+	}{
+		{
+			name:       "snapshot",
+			content:    snapshot,
+			signers:    []common.Address{signer},
+			wantHeight: 100,
+		},
+		{
+			name:    "snapshot without manifest",
+			content: noManifest,
+			signers: []common.Address{signer},
+			wantErr: ErrSnapshotUntrusted,
+		},
+		{
+			name:       "chain snapshot",
+			content:    chainSnapshot(t, key),
+			signers:    []common.Address{signer},
+			wantHeight: 100,
+		},
+		{
+			name:    "chain snapshot without signers",
+			content: chainSnapshot(t, key),
+			wantErr: ErrSnapshotUntrusted,
+		},
+		{
+			name:    "chain snapshot signed by other signer",
+			content: chainSnapshot(t, otherKey),
+			signers: []common.Address{signer},
+			wantErr: ErrSnapshotUntrusted,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			getter, err := parseFeedSnapshot(tc.content, tc.signers)
+			if err == nil {
+				f := NewSnapshotLogFilterer(log.Noop, getter)
+				f.SetTrustedSigners(tc.signers)
+				defer f.Close()
+
+				var height uint64
+				height, err = f.BlockNumber(context.Background())
+				if err == nil && height != tc.wantHeight {
+					t.Fatalf("got block height %d, want %d", height, tc.wantHeight)
+				}
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
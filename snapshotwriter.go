@@ -0,0 +1,151 @@
+package beelite
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/postage/listener"
+	"github.com/ethersphere/bee/v2/pkg/util/abiutil"
+)
+
+// DefaultSnapshotBlockRange is the number of blocks of the postage contract
+// events requested at once when a snapshot is written.
+const DefaultSnapshotBlockRange = 5000
+
+var errSnapshotRange = errors.New("invalid snapshot block range")
+
+// SnapshotManifest describes a postage snapshot file. The signature is made
+// over the hash of the file. FromBlock and ToBlock are the blocks whose events
+// the file holds, ToBlock is 0 in manifests without a block range.
+type SnapshotManifest struct {
+	FromBlock      uint64 `json:"fromBlock"`
+	ToBlock        uint64 `json:"toBlock,omitempty"`
+	MaxBlockHeight uint64 `json:"maxBlockHeight"`
+	LogCount       int    `json:"logCount"`
+	Hash           string `json:"hash"`
+	Signer         string `json:"signer,omitempty"`
+	Signature      string `json:"signature,omitempty"`
+}
+
+// SnapshotOptions select the postage contract events written by WriteSnapshot.
+type SnapshotOptions struct {
+	Contract common.Address
+	// ContractABI is the ABI of the postage contract, the mainnet one if empty.
+	ContractABI string
+	FromBlock   uint64
+	// ToBlock is the last block of the snapshot, the current block if 0.
+	ToBlock uint64
+	// BlockRange is the number of blocks requested at once, the
+	// DefaultSnapshotBlockRange if 0.
+	BlockRange uint64
+}
+
+// WriteSnapshot writes the events of the postage contract to w in the format
+// of SnapshotLogFilterer. The output only depends on the events, so snapshots
+// of the same block range are identical. A snapshot starting after the block
+// range of another one is a delta, see SnapshotLogFilterer.AddSnapshotDeltas.
+func WriteSnapshot(ctx context.Context, filterer listener.BlockHeightContractFilterer, o SnapshotOptions, w io.Writer) (*SnapshotManifest, error) {
+	from, to, blockRange := o.FromBlock, o.ToBlock, o.BlockRange
+	if to == 0 {
+		head, err := filterer.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("block number: %w", err)
+		}
+		to = head
+	}
+	if from > to {
+		return nil, fmt.Errorf("%w: from block %d after to block %d", errSnapshotRange, from, to)
+	}
+	if blockRange == 0 {
+		blockRange = DefaultSnapshotBlockRange
+	}
+
+	sum := sha256.New()
+	gzipWriter, err := gzip.NewWriterLevel(io.MultiWriter(w, sum), gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	contractABI := valueOrDefault(o.ContractABI, chaincfg.Mainnet.PostageStampABI)
+	query := postageEventsQuery(o.Contract, contractABI)
+	m := &SnapshotManifest{FromBlock: from, ToBlock: to}
+	for start := from; start <= to; start += blockRange {
+		end := min(start+blockRange-1, to)
+		query.FromBlock = new(big.Int).SetUint64(start)
+		query.ToBlock = new(big.Int).SetUint64(end)
+
+		logs, err := filterer.FilterLogs(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("filter logs from block %d to %d: %w", start, end, err)
+		}
+		for _, l := range logs {
+			if err := checkLogOrder(m.LogCount, l.BlockNumber, m.MaxBlockHeight); err != nil {
+				return nil, err
+			}
+			line, err := json.Marshal(l)
+			if err != nil {
+				return nil, fmt.Errorf("encode log: %w", err)
+			}
+			if _, err := gzipWriter.Write(append(line, '\n')); err != nil {
+				return nil, err
+			}
+			m.MaxBlockHeight = l.BlockNumber
+			m.LogCount++
+		}
+		if end == to {
+			break
+		}
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	m.Hash = hex.EncodeToString(sum.Sum(nil))
+	return m, nil
+}
+
+// Sign signs the hash of the snapshot with signer.
+func (m *SnapshotManifest) Sign(signer crypto.Signer) error {
+	digest, err := hex.DecodeString(m.Hash)
+	if err != nil {
+		return fmt.Errorf("snapshot hash: %w", err)
+	}
+	sig, err := signer.Sign(digest)
+	if err != nil {
+		return fmt.Errorf("sign snapshot: %w", err)
+	}
+	addr, err := signer.EthereumAddress()
+	if err != nil {
+		return err
+	}
+	m.Signer = addr.Hex()
+	m.Signature = hex.EncodeToString(sig)
+	return nil
+}
+
+// postageEventsQuery queries the events of the postage contract which the
+// postage listener processes.
+func postageEventsQuery(contract common.Address, contractABI string) ethereum.FilterQuery {
+	abi := abiutil.MustParseABI(contractABI)
+	return ethereum.FilterQuery{
+		Addresses: []common.Address{contract},
+		Topics: [][]common.Hash{{
+			abi.Events["BatchCreated"].ID,
+			abi.Events["BatchTopUp"].ID,
+			abi.Events["BatchDepthIncrease"].ID,
+			abi.Events["PriceUpdate"].ID,
+			abi.Events["Paused"].ID,
+		}},
+	}
+}
@@ -0,0 +1,80 @@
+package beelite
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/storageincentives/staking"
+)
+
+var (
+	ErrInsufficientStakeAmount = staking.ErrInsufficientStakeAmount
+	ErrInsufficientFunds       = staking.ErrInsufficientFunds
+	ErrInsufficientStake       = staking.ErrInsufficientStake
+	ErrStakingNotPaused        = staking.ErrNotPaused
+)
+
+// DepositStake approves and deposits the given amount of BZZ (in PLUR) into
+// the staking contract for the overlay of this node.
+func (bl *Beelite) DepositStake(amount *big.Int) (common.Hash, error) {
+	if !bl.chainEnabled() {
+		return common.Hash{}, ErrChainDisabled
+	}
+	tx, err := bl.stakingContract.DepositStake(bl.ctx, amount)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("deposit stake: %w", err)
+	}
+	return tx, nil
+}
+
+// GetStake returns the potential stake of the node, which is the amount
+// deposited in the staking contract.
+func (bl *Beelite) GetStake() (*big.Int, error) {
+	if !bl.chainEnabled() {
+		return nil, ErrChainDisabled
+	}
+	stake, err := bl.stakingContract.GetPotentialStake(bl.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get stake: %w", err)
+	}
+	return stake, nil
+}
+
+// WithdrawableStake returns the amount of stake that exceeds the amount
+// required by the current reserve capacity and can be withdrawn.
+func (bl *Beelite) WithdrawableStake() (*big.Int, error) {
+	if !bl.chainEnabled() {
+		return nil, ErrChainDisabled
+	}
+	stake, err := bl.stakingContract.GetWithdrawableStake(bl.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get withdrawable stake: %w", err)
+	}
+	return stake, nil
+}
+
+// WithdrawStake withdraws the withdrawable surplus stake to the node wallet.
+func (bl *Beelite) WithdrawStake() (common.Hash, error) {
+	if !bl.chainEnabled() {
+		return common.Hash{}, ErrChainDisabled
+	}
+	tx, err := bl.stakingContract.WithdrawStake(bl.ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("withdraw stake: %w", err)
+	}
+	return tx, nil
+}
+
+// MigrateStake withdraws the whole stake from a paused staking contract. It
+// returns ErrStakingNotPaused if the contract is still active.
+func (bl *Beelite) MigrateStake() (common.Hash, error) {
+	if !bl.chainEnabled() {
+		return common.Hash{}, ErrChainDisabled
+	}
+	tx, err := bl.stakingContract.MigrateStake(bl.ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("migrate stake: %w", err)
+	}
+	return tx, nil
+}
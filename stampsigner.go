@@ -0,0 +1,256 @@
+package beelite
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/crypto/eip712"
+	"github.com/ethersphere/bee/v2/pkg/postage"
+	storer "github.com/ethersphere/bee/v2/pkg/storer"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// stampSignBatchSize is the number of stamps sent to a StampSigner at once.
+const stampSignBatchSize = 128
+
+var (
+	errStampSignerOwner    = errors.New("stamp signer is not the owner of the batch")
+	errStampSignatureCount = errors.New("stamp signer returned wrong number of signatures")
+	errDeferredSigner      = errors.New("deferred signer only signs stamps")
+)
+
+// StampSigner signs postage stamps on behalf of the owner of a batch whose key
+// is not held by the node, e.g. in a hardware wallet, on another device or in
+// a remote service.
+type StampSigner interface {
+	// EthereumAddress returns the address of the batch owner.
+	EthereumAddress() (common.Address, error)
+	// SignStamps signs the stamp digests with the ethereum prefix (eip191),
+	// the same way as crypto.Signer.Sign, and returns the signatures in the
+	// order of the digests.
+	SignStamps(ctx context.Context, digests [][]byte) ([][]byte, error)
+}
+
+// NewStampSigner returns a StampSigner signing with the given signer.
+func NewStampSigner(signer crypto.Signer) StampSigner {
+	return &cryptoStampSigner{signer: signer}
+}
+
+type cryptoStampSigner struct {
+	signer crypto.Signer
+}
+
+func (s *cryptoStampSigner) EthereumAddress() (common.Address, error) {
+	return s.signer.EthereumAddress()
+}
+
+func (s *cryptoStampSigner) SignStamps(_ context.Context, digests [][]byte) ([][]byte, error) {
+	sigs := make([][]byte, len(digests))
+	for i, d := range digests {
+		sig, err := s.signer.Sign(d)
+		if err != nil {
+			return nil, err
+		}
+		sigs[i] = sig
+	}
+	return sigs, nil
+}
+
+// SetStampSigner makes uploads with the given batch sign their stamps with
+// signer instead of the node key. The batch has to be owned by the signer, a
+// stamp issuer is created for it if the node does not have one yet.
+func (bl *Beelite) SetStampSigner(batchHex string, signer StampSigner) error {
+	if !bl.chainEnabled() {
+		return ErrChainDisabled
+	}
+	batchID, err := hex.DecodeString(batchHex)
+	if err != nil {
+		return errInvalidPostageBatch
+	}
+
+	batch, err := bl.batchStore.Get(batchID)
+	if err != nil {
+		return fmt.Errorf("get batch: %w", err)
+	}
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		return fmt.Errorf("stamp signer address: %w", err)
+	}
+	if !bytes.Equal(owner.Bytes(), batch.Owner) {
+		return errStampSignerOwner
+	}
+
+	if _, _, err := bl.post.GetStampIssuer(batchID); errors.Is(err, postage.ErrNotFound) {
+		err = bl.post.Add(postage.NewStampIssuer("remote", hex.EncodeToString(batch.Owner), batch.ID, batch.Value, batch.Depth, batch.BucketDepth, batch.Start, batch.Immutable))
+		if err != nil {
+			return fmt.Errorf("add stamp issuer: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("stamp issuer: %w", err)
+	}
+
+	bl.stampSigners.set(batchID, &batchStampSigner{signer: signer, owner: batch.Owner})
+	return nil
+}
+
+// RemoveStampSigner makes uploads with the batch sign with the node key again.
+func (bl *Beelite) RemoveStampSigner(batchHex string) {
+	batchID, err := hex.DecodeString(batchHex)
+	if err != nil {
+		return
+	}
+	bl.stampSigners.set(batchID, nil)
+}
+
+type batchStampSigner struct {
+	signer StampSigner
+	owner  []byte
+}
+
+type stampSigners struct {
+	mu      sync.Mutex
+	signers map[string]*batchStampSigner
+}
+
+func (s *stampSigners) get(batchID []byte) *batchStampSigner {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.signers[hex.EncodeToString(batchID)]
+}
+
+func (s *stampSigners) set(batchID []byte, signer *batchStampSigner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batchHex := hex.EncodeToString(batchID)
+	if signer == nil {
+		delete(s.signers, batchHex)
+		return
+	}
+	if s.signers == nil {
+		s.signers = make(map[string]*batchStampSigner)
+	}
+	s.signers[batchHex] = signer
+}
+
+// deferredSigner is the signer of postage.NewStamper for batches with a
+// StampSigner. It puts the digest in place of the signature, so that the
+// stamps can be signed in batches before the chunks are stored.
+type deferredSigner struct{}
+
+func (deferredSigner) Sign(data []byte) ([]byte, error) {
+	return bytes.Clone(data), nil
+}
+
+func (deferredSigner) SignTx(*types.Transaction, *big.Int) (*types.Transaction, error) {
+	return nil, errDeferredSigner
+}
+
+func (deferredSigner) SignTypedData(*eip712.TypedData) ([]byte, error) {
+	return nil, errDeferredSigner
+}
+
+func (deferredSigner) PublicKey() (*ecdsa.PublicKey, error) {
+	return nil, errDeferredSigner
+}
+
+func (deferredSigner) EthereumAddress() (common.Address, error) {
+	return common.Address{}, errDeferredSigner
+}
+
+// stampSigningQueue collects the chunks stamped by a deferredSigner and
+// stores them once their stamps are signed by the StampSigner.
+type stampSigningQueue struct {
+	ctx     context.Context
+	signer  *batchStampSigner
+	session storer.PutterSession
+
+	mu      sync.Mutex
+	pending []swarm.Chunk
+}
+
+func (q *stampSigningQueue) put(chunk swarm.Chunk) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending = append(q.pending, chunk)
+	if len(q.pending) < stampSignBatchSize {
+		return nil
+	}
+	return q.flushLocked()
+}
+
+func (q *stampSigningQueue) flush() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.flushLocked()
+}
+
+func (q *stampSigningQueue) reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = nil
+}
+
+func (q *stampSigningQueue) flushLocked() error {
+	if len(q.pending) == 0 {
+		return nil
+	}
+
+	addrs := make([]swarm.Address, len(q.pending))
+	unsigned := make([]swarm.Stamp, len(q.pending))
+	for i, ch := range q.pending {
+		addrs[i] = ch.Address()
+		unsigned[i] = ch.Stamp()
+	}
+	stamps, err := q.signer.sign(q.ctx, addrs, unsigned)
+	if err != nil {
+		return err
+	}
+
+	for i, ch := range q.pending {
+		if err := q.session.Put(q.ctx, ch.WithStamp(stamps[i])); err != nil {
+			return err
+		}
+	}
+	q.pending = q.pending[:0]
+	return nil
+}
+
+// sign replaces the digests in the stamps issued with a deferredSigner by
+// the signatures of the StampSigner and checks that they recover to the batch owner.
+func (s *batchStampSigner) sign(ctx context.Context, addrs []swarm.Address, unsigned []swarm.Stamp) ([]*postage.Stamp, error) {
+	digests := make([][]byte, len(unsigned))
+	for i, st := range unsigned {
+		digests[i] = st.Sig()
+	}
+	sigs, err := s.signer.SignStamps(ctx, digests)
+	if err != nil {
+		return nil, fmt.Errorf("sign stamps: %w", err)
+	}
+	if len(sigs) != len(digests) {
+		return nil, errStampSignatureCount
+	}
+
+	stamps := make([]*postage.Stamp, len(unsigned))
+	for i, st := range unsigned {
+		stamp := postage.NewStamp(st.BatchID(), st.Index(), st.Timestamp(), sigs[i])
+		owner, err := postage.RecoverBatchOwner(addrs[i], stamp)
+		if err != nil {
+			return nil, fmt.Errorf("recover stamp signer: %w", err)
+		}
+		if !bytes.Equal(owner, s.owner) {
+			return nil, postage.ErrInvalidBatchSignature
+		}
+		stamps[i] = stamp
+	}
+	return stamps, nil
+}
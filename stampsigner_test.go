@@ -0,0 +1,42 @@
+package beelite
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/api"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/postage"
+	batchstoremock "github.com/ethersphere/bee/v2/pkg/postage/batchstore/mock"
+	postagemock "github.com/ethersphere/bee/v2/pkg/postage/mock"
+)
+
+func TestSetStampSignerBatchCase(t *testing.T) {
+	t.Parallel()
+
+	key, owner := testSignerKey(t)
+	batchID := make([]byte, 32)
+	batchID[0] = 0xab
+	batch := &postage.Batch{ID: batchID, Owner: owner.Bytes(), Value: big.NewInt(1), Depth: 20, BucketDepth: 16}
+
+	bl := &Beelite{
+		beeNodeMode: api.LightMode,
+		batchStore:  batchstoremock.New(batchstoremock.WithBatch(batch), batchstoremock.WithAcceptAllExistsFunc()),
+		post:        postagemock.New(postagemock.WithAcceptAll()),
+	}
+
+	upper := strings.ToUpper(hex.EncodeToString(batchID))
+	if err := bl.SetStampSigner(upper, NewStampSigner(crypto.NewDefaultSigner(key))); err != nil {
+		t.Fatal(err)
+	}
+	if bl.stampSigners.get(batchID) == nil {
+		t.Fatalf("stamp signer of batch %s not found", upper)
+	}
+
+	bl.RemoveStampSigner(upper)
+	if bl.stampSigners.get(batchID) != nil {
+		t.Fatalf("stamp signer of batch %s not removed", upper)
+	}
+}
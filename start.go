@@ -4,16 +4,16 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
-	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
-	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
 	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/keystore"
 	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
@@ -25,27 +25,113 @@ import (
 )
 
 type LiteOptions struct {
-	FullNodeMode             bool
-	BootnodeMode             bool
-	Bootnodes                []string
-	StaticNodes              []string
-	DataDir                  string
-	WelcomeMessage           string
-	BlockchainRpcEndpoint    string
-	SwapInitialDeposit       string
-	PaymentThreshold         string
-	SwapEnable               bool
-	ChequebookEnable         bool
-	UsePostageSnapshot       bool
-	Mainnet                  bool
-	NetworkID                uint64
-	NATAddr                  string
-	CacheCapacity            uint64
-	DBOpenFilesLimit         uint64
-	DBWriteBufferSize        uint64
-	DBBlockCacheCapacity     uint64
-	DBDisableSeeksCompaction bool
-	RetrievalCaching         bool
+	FullNodeMode             bool     `yaml:"full-node"`
+	BootnodeMode             bool     `yaml:"bootnode-mode"`
+	Bootnodes                []string `yaml:"bootnodes"`
+	StaticNodes              []string `yaml:"static-nodes"`
+	DataDir                  string   `yaml:"data-dir"`
+	WelcomeMessage           string   `yaml:"welcome-message"`
+	BlockchainRpcEndpoint    string   `yaml:"blockchain-rpc-endpoint"`
+	SwapInitialDeposit       string   `yaml:"swap-initial-deposit"`
+	PaymentThreshold         string   `yaml:"payment-threshold"`
+	SwapEnable               bool     `yaml:"swap-enable"`
+	ChequebookEnable         bool     `yaml:"chequebook-enable"`
+	UsePostageSnapshot       bool     `yaml:"use-postage-snapshot"` // Deprecated: new nodes use the postage snapshot unless SkipPostageSnapshot is set, see PostageSnapshotFeed.
+	Mainnet                  bool     `yaml:"mainnet"`
+	NetworkID                uint64   `yaml:"network-id"`
+	NATAddr                  string   `yaml:"nat-addr"`
+	CacheCapacity            uint64   `yaml:"cache-capacity"`
+	DBOpenFilesLimit         uint64   `yaml:"db-open-files-limit"`
+	DBWriteBufferSize        uint64   `yaml:"db-write-buffer-size"`
+	DBBlockCacheCapacity     uint64   `yaml:"db-block-cache-capacity"`
+	DBDisableSeeksCompaction bool     `yaml:"db-disable-seeks-compaction"`
+	RetrievalCaching         bool     `yaml:"retrieval-caching"`
+
+	// The options below were fixed in earlier versions. Their zero value keeps
+	// the previous behaviour, see the defaults in options.go.
+	APIAddr                       string        `yaml:"api-addr"`
+	P2PAddr                       string        `yaml:"p2p-addr"`
+	EnableWS                      bool          `yaml:"p2p-ws-enable"`
+	CORSAllowedOrigins            []string      `yaml:"cors-allowed-origins"`
+	AllowPrivateCIDRs             bool          `yaml:"allow-private-cidrs"`
+	TracingEnabled                bool          `yaml:"tracing-enable"`
+	TracingEndpoint               string        `yaml:"tracing-endpoint"`
+	ResolverEndpoints             []string      `yaml:"resolver-options"`
+	PaymentTolerance              *int64        `yaml:"payment-tolerance-percent"`
+	PaymentEarly                  *int64        `yaml:"payment-early-percent"`
+	BlockTime                     time.Duration `yaml:"block-time"`
+	WarmupTime                    time.Duration `yaml:"warmup-time"`
+	StatestoreCacheCapacity       uint64        `yaml:"statestore-cache-capacity"`
+	TargetNeighborhood            string        `yaml:"target-neighborhood"`
+	MinimumStorageRadius          uint          `yaml:"minimum-storage-radius"`
+	ReserveCapacityDoubling       int           `yaml:"reserve-capacity-doubling"`
+	Resync                        bool          `yaml:"resync"`
+	SkipPostageSnapshot           bool          `yaml:"skip-postage-snapshot"`
+	DisableStorageIncentives      bool          `yaml:"storage-incentives-disable"`
+	SwapFactoryAddress            string        `yaml:"swap-factory-address"`
+	PostageContractAddress        string        `yaml:"postage-stamp-address"`
+	PostageContractStartBlock     uint64        `yaml:"postage-stamp-start-block"`
+	PriceOracleAddress            string        `yaml:"price-oracle-address"`
+	RedistributionContractAddress string        `yaml:"redistribution-address"`
+	StakingContractAddress        string        `yaml:"staking-address"`
+	WhitelistedWithdrawalAddress  []string      `yaml:"withdrawal-addresses-whitelist"`
+	TrxDebugMode                  bool          `yaml:"transaction-debug-mode"`
+
+	// Network is the profile of a custom network, e.g. a private swarm. It is
+	// read from NetworkProfileFile if not set.
+	Network            *NetworkProfile `yaml:"-"`
+	NetworkProfileFile string          `yaml:"network-profile"`
+
+	// KeyProvider supplies the node keys. If not set, the keys are read from
+	// the file keystore in DataDir, or kept in memory without DataDir, and
+	// decrypted with the password given to Start.
+	KeyProvider KeyProvider `yaml:"-"`
+
+	// ConfirmOverlayChange is asked before the start replaces the overlay
+	// address of the node with one in TargetNeighborhood. The start fails with
+	// ErrOverlayChangeRejected if it returns false. If not set, the overlay
+	// address is replaced.
+	ConfirmOverlayChange OverlayChangeConfirmFunc `yaml:"-"`
+
+	// NeighborhoodSuggester is the URL of the service suggesting the
+	// neighborhood of a new node without TargetNeighborhood. On mainnet it
+	// defaults to the swarmscan suggester, NeighborhoodSuggesterDisabled turns
+	// it off. NeighborhoodSuggesterFunc replaces the service if set.
+	NeighborhoodSuggester     string                    `yaml:"neighborhood-suggester"`
+	NeighborhoodSuggesterFunc NeighborhoodSuggesterFunc `yaml:"-"`
+
+	// HTTPClient makes all outbound HTTP requests of the node, to the
+	// blockchain and name resolver endpoints and the neighborhood suggester.
+	// If not set, a client using HTTPProxy, if given, is created.
+	HTTPClient *http.Client `yaml:"-"`
+	HTTPProxy  string       `yaml:"http-proxy"`
+
+	// The postage snapshot syncs the batches of a new node. The one compiled
+	// into bee-lite is used by default, otherwise it is read from a local
+	// file, downloaded from a URL with its SHA-256 checksum or retrieved from
+	// a feed manifest reference. PostageSnapshotGetter replaces them if set,
+	// it has to implement SnapshotManifestGetter.
+	PostageSnapshotFile     string         `yaml:"postage-snapshot-file"`
+	PostageSnapshotURL      string         `yaml:"postage-snapshot-url"`
+	PostageSnapshotChecksum string         `yaml:"postage-snapshot-checksum"`
+	PostageSnapshotFeed     string         `yaml:"postage-snapshot-feed"`
+	PostageSnapshotGetter   SnapshotGetter `yaml:"-"`
+	// PostageSnapshotSigners are the ethereum addresses trusted to sign
+	// postage snapshots. A snapshot from a file, URL or feed is only used
+	// with a manifest signed by one of them, that of a getter with a
+	// manifest signed by one of them if set.
+	PostageSnapshotSigners []string `yaml:"postage-snapshot-signers"`
+	// PostageSnapshotDeltas are snapshots of the blocks after the postage
+	// snapshot, local files or URLs with a manifest holding their block
+	// range, merged into it in the given order, followed by
+	// PostageSnapshotDeltaGetters, which have to implement
+	// SnapshotManifestGetter. A node with synced
+	// batches catches up from the deltas alone if they continue its blocks.
+	PostageSnapshotDeltas       []string         `yaml:"postage-snapshot-deltas"`
+	PostageSnapshotDeltaGetters []SnapshotGetter `yaml:"-"`
+	// StreamPostageSnapshot keeps the postage snapshot in an index file in
+	// DataDir instead of memory while the batches are synced from it.
+	StreamPostageSnapshot bool `yaml:"stream-postage-snapshot"`
 }
 
 type buildBeeliteNodeResp struct {
@@ -61,6 +147,19 @@ type signerConfig struct {
 	session          accesscontrol.Session
 }
 
+// liteNodeOptions are the options of NewBee which node.Options does not have.
+type liteNodeOptions struct {
+	confirmOverlayChange  OverlayChangeConfirmFunc
+	neighborhoodSuggester NeighborhoodSuggesterFunc
+	httpClient            *http.Client
+	snapshotGetter        SnapshotGetter
+	snapshotSource        string
+	snapshotDeltas        []SnapshotGetter
+	snapshotFeed          swarm.Address
+	streamSnapshot        bool
+	snapshotSigners       []common.Address
+}
+
 type networkConfig struct {
 	bootNodes []string
 	blockTime time.Duration
@@ -68,50 +167,41 @@ type networkConfig struct {
 }
 
 func configureSigner(lo *LiteOptions, password string, beelogger beelog.Logger) (config *signerConfig, err error) {
-	var keystore keystore.Service
-	const libp2pPKFilename = "libp2p_v2"
-	if lo.DataDir == "" {
-		keystore = memkeystore.New()
-		beelogger.Warning("data directory not provided, keys are not persisted")
-	} else {
-		keystore = filekeystore.New(filepath.Join(lo.DataDir, "keys"))
+	keys := lo.KeyProvider
+	if keys == nil {
+		var ks keystore.Service
+		if lo.DataDir == "" {
+			ks = memkeystore.New()
+			beelogger.Warning("data directory not provided, keys are not persisted")
+		} else {
+			ks = filekeystore.New(filepath.Join(lo.DataDir, keysDir))
+		}
+		keys = NewKeystoreKeyProvider(ks, password)
 	}
 
-	var signer crypto.Signer
-	var publicKey *ecdsa.PublicKey
-	var session accesscontrol.Session
-	if p := password; p != "" {
-		password = p
+	signer, err := keys.Signer()
+	if err != nil {
+		return nil, err
 	}
-
-	swarmPrivateKey, _, err := keystore.Key("swarm", password, crypto.EDGSecp256_K1)
+	publicKey, err := signer.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("swarm public key: %w", err)
+	}
+	session, err := keys.Session()
 	if err != nil {
-		return nil, fmt.Errorf("swarm key: %w", err)
+		return nil, err
 	}
-	signer = crypto.NewDefaultSigner(swarmPrivateKey)
-	publicKey = &swarmPrivateKey.PublicKey
-	session = accesscontrol.NewDefaultSession(swarmPrivateKey)
 
 	beelogger.Info("swarm public key", "public_key", hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(publicKey)))
 
-	libp2pPrivateKey, created, err := keystore.Key(libp2pPKFilename, password, crypto.EDGSecp256_R1)
+	libp2pPrivateKey, err := keys.Libp2pKey()
 	if err != nil {
-		return nil, fmt.Errorf("libp2p v2 key: %w", err)
-	}
-	if created {
-		beelogger.Debug("new libp2p v2 key created")
-	} else {
-		beelogger.Debug("using existing libp2p key")
+		return nil, err
 	}
 
-	pssPrivateKey, created, err := keystore.Key("pss", password, crypto.EDGSecp256_K1)
+	pssPrivateKey, err := keys.PSSKey()
 	if err != nil {
-		return nil, fmt.Errorf("pss key: %w", err)
-	}
-	if created {
-		beelogger.Debug("new pss key created")
-	} else {
-		beelogger.Debug("using existing pss key")
+		return nil, err
 	}
 
 	beelogger.Info("pss public key", "public_key", hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&pssPrivateKey.PublicKey)))
@@ -145,102 +235,136 @@ func buildBeeNodeAsync(ctx context.Context, lo *LiteOptions, password string, be
 func buildBeeNode(ctx context.Context, lo *LiteOptions, password string, beelogger beelog.Logger) (*Beelite, error) {
 	var err error
 
-	signerCfg, err := configureSigner(lo, password, beelogger)
+	network, err := lo.networkProfile()
 	if err != nil {
 		return nil, err
 	}
+	if network != nil {
+		lo = lo.withNetwork(network)
+	}
 
-	bootnodeMode := lo.BootnodeMode
-	fullNodeMode := lo.FullNodeMode
-	if bootnodeMode && !fullNodeMode {
-		return nil, errors.New("boot node must be started as a full node")
+	if err := lo.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
 	}
 
-	mainnet := lo.Mainnet
-	networkID := lo.NetworkID
-	if mainnet && networkID != chaincfg.Mainnet.NetworkID {
-		return nil, errors.New("provided network ID does not match mainnet")
+	signerCfg, err := configureSigner(lo, password, beelogger)
+	if err != nil {
+		return nil, err
 	}
 
-	bootnodes := lo.Bootnodes
+	networkID := lo.NetworkID
 	networkCfg := getConfigByNetworkID(networkID)
+	if network != nil {
+		networkCfg.chainID = network.ChainID
+	}
 	if lo.Bootnodes != nil {
-		networkCfg.bootNodes = bootnodes
+		networkCfg.bootNodes = lo.Bootnodes
+	}
+	if lo.BlockTime != 0 {
+		networkCfg.blockTime = lo.BlockTime
 	}
 
-	staticNodesOpt := []string{}
-	if nil != lo.StaticNodes {
-		staticNodesOpt = lo.StaticNodes
+	staticNodes, err := parseStaticNodes(lo.StaticNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	resolverCfgs, err := multiresolver.ParseConnectionStrings(lo.ResolverEndpoints)
+	if err != nil {
+		return nil, fmt.Errorf("resolver options: %w", err)
 	}
-	staticNodes := make([]swarm.Address, 0, len(staticNodesOpt))
-	for _, p := range staticNodesOpt {
-		addr, err := swarm.ParseHexAddress(p)
-		if err != nil {
-			return nil, fmt.Errorf("invalid swarm address %q configured for static node", p)
-		}
 
-		staticNodes = append(staticNodes, addr)
+	snapshotFeed := swarm.ZeroAddress
+	if lo.PostageSnapshotFeed != "" {
+		snapshotFeed = swarm.MustParseHexAddress(lo.PostageSnapshotFeed)
 	}
-	if len(staticNodes) > 0 && !bootnodeMode {
-		return nil, errors.New("static nodes can only be configured on bootnodes")
+
+	snapshotSigners := make([]common.Address, 0, len(lo.PostageSnapshotSigners))
+	for _, s := range lo.PostageSnapshotSigners {
+		snapshotSigners = append(snapshotSigners, common.HexToAddress(s))
 	}
 
-	var neighborhoodSuggester string
-	if networkID == chaincfg.Mainnet.NetworkID {
-		neighborhoodSuggester = "https://api.swarmscan.io/v1/network/neighborhoods/suggestion"
+	client, err := lo.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	client, endpoints := recordEndpoints(client)
+	defer func() {
+		beelogger.Info("external endpoints contacted during startup", "endpoints", endpoints.list())
+	}()
+
+	p2pAddr := valueOrDefault(lo.P2PAddr, defaultP2PAddr)
+	corsAllowedOrigins := lo.CORSAllowedOrigins
+	if corsAllowedOrigins == nil {
+		corsAllowedOrigins = []string{"*"}
+	}
+	whitelistedWithdrawalAddress := lo.WhitelistedWithdrawalAddress
+	if whitelistedWithdrawalAddress == nil {
+		whitelistedWithdrawalAddress = []string{}
 	}
 
-	beelite, err := NewBee(ctx, ":1634", signerCfg.publicKey, signerCfg.signer, networkID, beelogger, signerCfg.libp2pPrivateKey, signerCfg.pssPrivateKey, signerCfg.session, &node.Options{
+	beelite, err := NewBee(ctx, p2pAddr, signerCfg.publicKey, signerCfg.signer, networkID, beelogger, signerCfg.libp2pPrivateKey, signerCfg.pssPrivateKey, signerCfg.session, &liteNodeOptions{
+		confirmOverlayChange:  lo.ConfirmOverlayChange,
+		neighborhoodSuggester: lo.neighborhoodSuggester(client),
+		httpClient:            client,
+		snapshotGetter:        lo.snapshotGetter(client),
+		snapshotSource:        lo.snapshotSource(),
+		snapshotDeltas:        lo.snapshotDeltas(client),
+		snapshotFeed:          snapshotFeed,
+		streamSnapshot:        lo.StreamPostageSnapshot,
+		snapshotSigners:       snapshotSigners,
+	}, &node.Options{
 		DataDir:                       lo.DataDir,
 		CacheCapacity:                 lo.CacheCapacity,
 		DBOpenFilesLimit:              lo.DBOpenFilesLimit,
 		DBBlockCacheCapacity:          lo.DBBlockCacheCapacity,
 		DBWriteBufferSize:             lo.DBWriteBufferSize,
 		DBDisableSeeksCompaction:      lo.DBDisableSeeksCompaction,
-		APIAddr:                       ":1633",
-		Addr:                          ":1634",
+		APIAddr:                       valueOrDefault(lo.APIAddr, defaultAPIAddr),
+		Addr:                          p2pAddr,
 		NATAddr:                       lo.NATAddr,
-		EnableWS:                      false,
+		EnableWS:                      lo.EnableWS,
 		WelcomeMessage:                lo.WelcomeMessage,
 		Bootnodes:                     networkCfg.bootNodes,
-		CORSAllowedOrigins:            []string{"*"},
-		TracingEnabled:                false,
-		TracingEndpoint:               ":6831",
+		CORSAllowedOrigins:            corsAllowedOrigins,
+		TracingEnabled:                lo.TracingEnabled,
+		TracingEndpoint:               valueOrDefault(lo.TracingEndpoint, defaultTracingEndpoint),
 		TracingServiceName:            LoggerName,
 		Logger:                        beelogger,
 		PaymentThreshold:              lo.PaymentThreshold,
-		PaymentTolerance:              25,
-		PaymentEarly:                  50,
-		ResolverConnectionCfgs:        []multiresolver.ConnectionConfig{},
-		BootnodeMode:                  bootnodeMode,
+		PaymentTolerance:              pointerOrDefault(lo.PaymentTolerance, defaultPaymentTolerance),
+		PaymentEarly:                  pointerOrDefault(lo.PaymentEarly, defaultPaymentEarly),
+		ResolverConnectionCfgs:        resolverCfgs,
+		BootnodeMode:                  lo.BootnodeMode,
 		BlockchainRpcEndpoint:         lo.BlockchainRpcEndpoint,
-		SwapFactoryAddress:            "",
+		SwapFactoryAddress:            lo.SwapFactoryAddress,
 		SwapInitialDeposit:            lo.SwapInitialDeposit,
 		SwapEnable:                    lo.SwapEnable,
 		ChequebookEnable:              lo.ChequebookEnable,
-		FullNodeMode:                  fullNodeMode,
-		PostageContractAddress:        "",
-		PostageContractStartBlock:     0,
-		PriceOracleAddress:            "",
-		RedistributionContractAddress: "",
-		StakingContractAddress:        "",
+		FullNodeMode:                  lo.FullNodeMode,
+		PostageContractAddress:        lo.PostageContractAddress,
+		PostageContractStartBlock:     lo.PostageContractStartBlock,
+		PriceOracleAddress:            lo.PriceOracleAddress,
+		RedistributionContractAddress: lo.RedistributionContractAddress,
+		StakingContractAddress:        lo.StakingContractAddress,
 		BlockTime:                     networkCfg.blockTime,
-		WarmupTime:                    0,
+		WarmupTime:                    lo.WarmupTime,
 		ChainID:                       networkCfg.chainID,
 		RetrievalCaching:              lo.RetrievalCaching,
-		Resync:                        false,
+		Resync:                        lo.Resync,
 		BlockProfile:                  false,
 		MutexProfile:                  false,
 		StaticNodes:                   staticNodes,
-		AllowPrivateCIDRs:             false,
+		AllowPrivateCIDRs:             lo.AllowPrivateCIDRs,
 		UsePostageSnapshot:            lo.UsePostageSnapshot,
-		EnableStorageIncentives:       true,
-		StatestoreCacheCapacity:       1000000,
-		TargetNeighborhood:            "",
-		NeighborhoodSuggester:         neighborhoodSuggester,
-		WhitelistedWithdrawalAddress:  []string{},
-		TrxDebugMode:                  false,
-		MinimumStorageRadius:          0,
+		SkipPostageSnapshot:           lo.SkipPostageSnapshot,
+		EnableStorageIncentives:       !lo.DisableStorageIncentives,
+		StatestoreCacheCapacity:       valueOrDefault(lo.StatestoreCacheCapacity, defaultStatestoreCacheCapacity),
+		TargetNeighborhood:            lo.TargetNeighborhood,
+		WhitelistedWithdrawalAddress:  whitelistedWithdrawalAddress,
+		TrxDebugMode:                  lo.TrxDebugMode,
+		MinimumStorageRadius:          lo.MinimumStorageRadius,
+		ReserveCapacityDoubling:       lo.ReserveCapacityDoubling,
 	})
 
 	return beelite, err
@@ -10,15 +10,23 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
+	"github.com/ethersphere/bee/v2/pkg/accounting"
+	"github.com/ethersphere/bee/v2/pkg/addressbook"
 	"github.com/ethersphere/bee/v2/pkg/api"
 	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
 	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/feeds"
 	beelog "github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/pingpong"
 	"github.com/ethersphere/bee/v2/pkg/postage"
 	"github.com/ethersphere/bee/v2/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/v2/pkg/settlement"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/storageincentives"
+	"github.com/ethersphere/bee/v2/pkg/storageincentives/staking"
 	storer "github.com/ethersphere/bee/v2/pkg/storer"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 	"github.com/ethersphere/bee/v2/pkg/topology"
@@ -38,23 +46,37 @@ const (
 )
 
 type Beelite struct {
-	bee                *Bee
-	overlayEthAddress  common.Address
-	publicKey          *ecdsa.PublicKey
-	feedFactory        feeds.Factory
-	storer             api.Storer
-	logger             beelog.Logger
-	topologyDriver     topology.Driver
-	ctx                context.Context
-	chequebookSvc      chequebook.Service
-	post               postage.Service
-	accesscontrol      accesscontrol.Controller
-	signer             crypto.Signer
-	postageContract    postagecontract.Interface
-	stamperStore       storage.Store
-	batchStore         postage.Storer
-	beeNodeMode        api.BeeNodeMode
-	transactionService transaction.Service
+	bee                 *Bee
+	overlayEthAddress   common.Address
+	publicKey           *ecdsa.PublicKey
+	feedFactory         feeds.Factory
+	storer              api.Storer
+	logger              beelog.Logger
+	topologyDriver      topology.Driver
+	ctx                 context.Context
+	chequebookSvc       chequebook.Service
+	post                postage.Service
+	accesscontrol       accesscontrol.Controller
+	signer              crypto.Signer
+	postageContract     postagecontract.Interface
+	stamperStore        storage.Store
+	batchStore          postage.Storer
+	beeNodeMode         api.BeeNodeMode
+	transactionService  transaction.Service
+	overlay             swarm.Address
+	p2pService          p2p.Service
+	pingpong            pingpong.Interface
+	addressBook         addressbook.Interface
+	stateStore          storage.StateStorer
+	networkID           uint64
+	addressResolver     *ensAddressResolver
+	accounting          *accounting.Accounting
+	pseudosettle        settlement.Interface
+	swap                *swap.Service
+	stakingContract     staking.Contract
+	redistributionAgent *storageincentives.Agent
+	redistribution      *redistributionControl
+	stampSigners        stampSigners
 }
 
 type putterOptions struct {
@@ -68,6 +90,8 @@ type putterSessionWrapper struct {
 	storer.PutterSession
 	stamper postage.Stamper
 	save    func() error
+	// signing is set if the stamps are signed by a StampSigner.
+	signing *stampSigningQueue
 }
 
 // noOpChequebookService is a noOp implementation for chequebook.Service interface.
@@ -160,6 +184,9 @@ var (
 	errBatchUnusable               = errors.New("batch not usable")
 	errUnsupportedDevNodeOperation = errors.New("operation not supported in dev mode")
 	errInvalidPostageBatch         = errors.New("invalid postage batch id")
+	// ErrChainDisabled is returned by operations which need a blockchain
+	// backend when the node runs without one.
+	ErrChainDisabled = postagecontract.ErrChainDisabled
 )
 
 func (p *putterSessionWrapper) Put(ctx context.Context, chunk swarm.Chunk) error {
@@ -172,14 +199,26 @@ func (p *putterSessionWrapper) Put(ctx context.Context, chunk swarm.Chunk) error
 	if err != nil {
 		return err
 	}
+	if p.signing != nil {
+		return p.signing.put(chunk.WithStamp(stamp))
+	}
 	return p.PutterSession.Put(ctx, chunk.WithStamp(stamp))
 }
 
 func (p *putterSessionWrapper) Done(ref swarm.Address) error {
+	if p.signing != nil {
+		if err := p.signing.flush(); err != nil {
+			p.signing.reset()
+			return errors.Join(err, p.PutterSession.Cleanup(), p.save())
+		}
+	}
 	return errors.Join(p.PutterSession.Done(ref), p.save())
 }
 
 func (p *putterSessionWrapper) Cleanup() error {
+	if p.signing != nil {
+		p.signing.reset()
+	}
 	return errors.Join(p.PutterSession.Cleanup(), p.save())
 }
 
@@ -191,7 +230,7 @@ func (bl *Beelite) Shutdown() error {
 	return bl.bee.Shutdown()
 }
 
-func (bl *Beelite) getStamper(batchID []byte) (postage.Stamper, func() error, error) {
+func (bl *Beelite) getStamper(batchID []byte, signer crypto.Signer) (postage.Stamper, func() error, error) {
 	exists, err := bl.batchStore.Exists(batchID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("batch exists: %w", err)
@@ -206,7 +245,7 @@ func (bl *Beelite) getStamper(batchID []byte) (postage.Stamper, func() error, er
 		return nil, nil, errBatchUnusable
 	}
 
-	return postage.NewStamper(bl.stamperStore, issuer, bl.signer), save, nil
+	return postage.NewStamper(bl.stamperStore, issuer, signer), save, nil
 }
 
 func (bl *Beelite) newStamperPutter(ctx context.Context, opts putterOptions) (storer.PutterSession, error) {
@@ -214,7 +253,19 @@ func (bl *Beelite) newStamperPutter(ctx context.Context, opts putterOptions) (st
 		return nil, errUnsupportedDevNodeOperation
 	}
 
-	stamper, save, err := bl.getStamper(opts.BatchID)
+	// Batches cannot be bought or validated without a chain, uploads need
+	// pre-signed stamps in ultra-light mode.
+	if !bl.chainEnabled() {
+		return nil, ErrChainDisabled
+	}
+
+	var signer crypto.Signer = bl.signer
+	stampSigner := bl.stampSigners.get(opts.BatchID)
+	if stampSigner != nil {
+		signer = deferredSigner{}
+	}
+
+	stamper, save, err := bl.getStamper(opts.BatchID, signer)
 	if err != nil {
 		return nil, fmt.Errorf("get stamper: %w", err)
 	}
@@ -230,10 +281,16 @@ func (bl *Beelite) newStamperPutter(ctx context.Context, opts putterOptions) (st
 		return nil, fmt.Errorf("failed creating session: %w", err)
 	}
 
+	var signing *stampSigningQueue
+	if stampSigner != nil {
+		signing = &stampSigningQueue{ctx: ctx, signer: stampSigner, session: session}
+	}
+
 	return &putterSessionWrapper{
 		PutterSession: session,
 		stamper:       stamper,
 		save:          save,
+		signing:       signing,
 	}, nil
 }
 
@@ -242,12 +299,21 @@ func (bl *Beelite) newStampedPutter(ctx context.Context, opts putterOptions, sta
 		return nil, errUnsupportedDevNodeOperation
 	}
 
-	storedBatch, err := bl.batchStore.Get(stamp.BatchID())
-	if err != nil {
-		return nil, errInvalidPostageBatch
+	var stamper postage.Stamper
+	if bl.chainEnabled() {
+		storedBatch, err := bl.batchStore.Get(stamp.BatchID())
+		if err != nil {
+			return nil, errInvalidPostageBatch
+		}
+		stamper = postage.NewPresignedStamper(stamp, storedBatch.Owner)
+	} else {
+		stamper = &unverifiedStamper{stamp: stamp}
 	}
 
-	var session storer.PutterSession
+	var (
+		session storer.PutterSession
+		err     error
+	)
 	if opts.Deferred || opts.Pin {
 		session, err = bl.storer.Upload(ctx, opts.Pin, opts.TagID)
 		if err != nil {
@@ -257,8 +323,6 @@ func (bl *Beelite) newStampedPutter(ctx context.Context, opts putterOptions, sta
 		session = bl.storer.DirectUpload()
 	}
 
-	stamper := postage.NewPresignedStamper(stamp, storedBatch.Owner)
-
 	return &putterSessionWrapper{
 		PutterSession: session,
 		stamper:       stamper,
@@ -266,6 +330,24 @@ func (bl *Beelite) newStampedPutter(ctx context.Context, opts putterOptions, sta
 	}, nil
 }
 
+// unverifiedStamper attaches a pre-signed stamp in ultra-light mode. Without
+// a batch store the batch owner is unknown, so only the signature is checked
+// to be valid for the chunk, the stamp itself is validated by the storer nodes.
+type unverifiedStamper struct {
+	stamp *postage.Stamp
+}
+
+func (s *unverifiedStamper) Stamp(addr, _ swarm.Address) (*postage.Stamp, error) {
+	if _, err := postage.RecoverBatchOwner(addr, s.stamp); err != nil {
+		return nil, err
+	}
+	return s.stamp, nil
+}
+
+func (s *unverifiedStamper) BatchId() []byte {
+	return s.stamp.BatchID()
+}
+
 // getOrCreateSessionID attempts to get the session if an tag id is supplied, and returns an error
 // if it does not exist. If no id is supplied, it will attempt to create a new session and return it.
 func (bl *Beelite) getOrCreateSessionID(tagUid uint64) (uint64, error) {
@@ -327,6 +409,9 @@ func (bl *Beelite) ChequebookAddr() common.Address {
 }
 
 func (bl *Beelite) ChequebookBalance() (*big.Int, error) {
+	if !bl.chainEnabled() {
+		return nil, ErrChainDisabled
+	}
 	if bl.chequebookSvc != nil {
 		return bl.chequebookSvc.Balance(bl.ctx)
 	}
@@ -334,6 +419,9 @@ func (bl *Beelite) ChequebookBalance() (*big.Int, error) {
 }
 
 func (bl *Beelite) ChequebookWithdraw(amount *big.Int) (common.Hash, error) {
+	if !bl.chainEnabled() {
+		return common.Hash{}, ErrChainDisabled
+	}
 	if bl.chequebookSvc != nil {
 		return bl.chequebookSvc.Withdraw(bl.ctx, amount)
 	}
@@ -348,10 +436,19 @@ func (bl *Beelite) BeeNodeMode() api.BeeNodeMode {
 	return bl.beeNodeMode
 }
 
+func (bl *Beelite) chainEnabled() bool {
+	return bl.beeNodeMode != api.UltraLightMode
+}
+
 func (bl *Beelite) ConnectedPeerCount() int {
 	return bl.topologyDriver.Snapshot().Connected
 }
 
-func (bl *Beelite) TransactionService() transaction.Service {
-	return bl.transactionService
+// TransactionService returns the blockchain transaction service, or
+// ErrChainDisabled in ultra-light mode.
+func (bl *Beelite) TransactionService() (transaction.Service, error) {
+	if !bl.chainEnabled() {
+		return nil, ErrChainDisabled
+	}
+	return bl.transactionService, nil
 }